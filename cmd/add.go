@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
@@ -131,8 +132,9 @@ var addReleaseCmd = &cobra.Command{
 			},
 		},
 		ui.Arg[string]{
-			Name: "namespace",
-			Ptr:  &Args.Namespace,
+			Name:      "namespace",
+			Ptr:       &Args.Namespace,
+			DependsOn: []string{"name"},
 			Callback: func(ns string) error {
 				Args.Namespace = strings.ToLower(ns)
 
@@ -149,7 +151,8 @@ var addReleaseCmd = &cobra.Command{
 
 				return nil
 			},
-			Validator: types.NameValidator("namespace", false),
+			Validator:   types.NameValidator("namespace", false),
+			Completions: NamespaceFuture,
 			UI: ui.PromptUiSelectorNewFunc[string]("Namespace", "", func(i int) error {
 				ns, err := NamespaceFuture.Get()
 				if err != nil {
@@ -175,8 +178,9 @@ var addReleaseCmd = &cobra.Command{
 			})),
 		},
 		ui.Arg[string]{
-			Name: "version",
-			Ptr:  &Args.AddReleaseCmd.Version,
+			Name:      "version",
+			Ptr:       &Args.AddReleaseCmd.Version,
+			DependsOn: []string{"chart"},
 			Validator: types.EqualValidator(
 				types.StringerFunc(func() string {
 					return fmt.Sprintf(`"%s" is not a version of "%s"`, "%s", Args.AddReleaseCmd.Chart)
@@ -243,8 +247,9 @@ var addReleaseCmd = &cobra.Command{
 			UI:        ui.PromptUiFunc[string]("Do you want to provide input values"),
 		},
 		ui.Arg[bool]{
-			Name: "force",
-			Ptr:  &Args.Force,
+			Name:      "force",
+			Ptr:       &Args.Force,
+			DependsOn: []string{"name"},
 			Disabled: types.FutureFromFunc(func() bool {
 				_, err := os.Stat(ReleasePath(Args.Name))
 				return err != nil
@@ -253,7 +258,7 @@ var addReleaseCmd = &cobra.Command{
 			Callback: func(force bool) error {
 				_, err := os.Stat(ReleasePath(Args.Name))
 				if !force && err == nil {
-					logger.Fatal("Release file already exists, use --force to overwrite it")
+					return fmt.Errorf("the release file already exists, use --force to overwrite it")
 				}
 
 				return nil
@@ -304,6 +309,18 @@ var addReleaseCmd = &cobra.Command{
 			logger.Fatal(err)
 		}
 
+		chartToDeploy := types.HelmChart(chart)
+		if Args.Deploy {
+			chartToDeploy = vendorForDeploy(release, chartToDeploy)
+
+			var cleanup func()
+			chartToDeploy, cleanup, err = LockChart(result, chartToDeploy)
+			defer cleanup()
+			if err != nil {
+				logger.Fatal(err)
+			}
+		}
+
 		if !Args.DryRun {
 			if err = os.WriteFile(ReleasePath(Args.Name), result.Document, 0644); err != nil {
 				logger.Fatalf("failed to write release file: %s", err)
@@ -313,8 +330,17 @@ var addReleaseCmd = &cobra.Command{
 			logger.Info("Dry run, not writing manifest or release file")
 		}
 
+		Audit(types.AuditEntry{
+			Action:     types.AuditActionAdd,
+			Kind:       "release",
+			Name:       release.Name,
+			Namespace:  release.Namespace,
+			NewVersion: release.Chart.Version,
+			ValuesHash: ValuesHash(result.EnvSubbedValues),
+		}, nil)
+
 		if Args.Deploy {
-			err = DeployRelease(release, types.HelmChart(chart), result.EnvSubbedValues, result.EnvSubbedDocument)
+			err = DeployRelease(release, chartToDeploy, result.EnvSubbedValues, result.EnvSubbedDocument)
 			if err != nil {
 				logger.Fatal(err)
 			}
@@ -349,9 +375,10 @@ var addSingleCmd = &cobra.Command{
 			UI:         ui.PromptUiFunc[string]("File"),
 		},
 		ui.Arg[string]{
-			Name:      "namespace",
-			Ptr:       &Args.Namespace,
-			Validator: types.NameValidator("namespace", true),
+			Name:        "namespace",
+			Ptr:         &Args.Namespace,
+			Validator:   types.NameValidator("namespace", true),
+			Completions: NamespaceFuture,
 			UI: ui.PromptUiSelectorNewFunc[string]("Namespace", "", func(i int) error {
 				if i == 0 {
 					Args.Namespace = ""
@@ -393,8 +420,9 @@ var addSingleCmd = &cobra.Command{
 			UI:         ui.PromptUiConfirmFunc("Do you want to apply the creation to the cluster", false),
 		},
 		ui.Arg[bool]{
-			Name: "force",
-			Ptr:  &Args.Force,
+			Name:      "force",
+			Ptr:       &Args.Force,
+			DependsOn: []string{"name"},
 			Disabled: types.FutureFromFunc(func() bool {
 				_, err := os.Stat(SinglePath(Args.Name))
 				return err != nil
@@ -403,7 +431,7 @@ var addSingleCmd = &cobra.Command{
 			Callback: func(force bool) error {
 				_, err := os.Stat(SinglePath(Args.Name))
 				if !force && err == nil {
-					logger.Fatal("Single file exists, use --force aborted")
+					return fmt.Errorf("the single file already exists, use --force to overwrite it")
 				}
 
 				return nil
@@ -441,6 +469,14 @@ var addSingleCmd = &cobra.Command{
 
 		utils.WriteManifest(Args.Context)
 
+		Audit(types.AuditEntry{
+			Action:     types.AuditActionAdd,
+			Kind:       "single",
+			Name:       single.Name,
+			Namespace:  single.Namespace,
+			ValuesHash: ValuesHash(data),
+		}, nil)
+
 		logger.Info("Single added to the manifest")
 
 		if Args.Deploy {
@@ -503,7 +539,7 @@ var addRepoCmd = &cobra.Command{
 			Name: Args.Name,
 			URL:  Args.AddRepoCmd.URL,
 		}
-		Manifest.Repos = append(Manifest.Repos)
+		Manifest.Repos = append(Manifest.Repos, repo)
 
 		done := utils.Loader(utils.LoaderOptions{
 			FetchingText: "Adding repo to manifest",
@@ -511,15 +547,18 @@ var addRepoCmd = &cobra.Command{
 			FailureText:  "Failed to add repo to manifest",
 		})
 
+		ctx, cancel := OperationContext(types.TimeoutRepo)
+		defer cancel()
+
 		if !exists {
-			resp, err := external.Helm.AddRepo(repo)
+			resp, err := external.Helm.AddRepo(ctx, repo)
 			if err != nil {
 				done(false)
 				logger.Fatalf("Failed to execute helm command: %v\n%s", err, resp)
 			}
 		}
 
-		resp, err := external.Helm.UpdateRepos()
+		resp, err := external.Helm.UpdateRepos(ctx)
 		if err != nil {
 			done(false)
 			logger.Fatalf("Failed to execute helm command: %v\n%s", err, resp)
@@ -528,6 +567,12 @@ var addRepoCmd = &cobra.Command{
 		done(true)
 
 		utils.WriteManifest(Args.Context)
+
+		Audit(types.AuditEntry{
+			Action: types.AuditActionAdd,
+			Kind:   "repo",
+			Name:   repo.Name,
+		}, nil)
 	},
 }
 
@@ -564,6 +609,12 @@ var addEnvCmd = &cobra.Command{
 			logger.Info("Dry run mode, not writing manifest")
 		}
 
+		Audit(types.AuditEntry{
+			Action: types.AuditActionAdd,
+			Kind:   "env",
+			Name:   string(env),
+		}, nil)
+
 		logger.Infof("Added %s to the env variable whitelist", color.GreenString(string(env)))
 	},
 }
@@ -588,14 +639,12 @@ var addLocalChartCmd = &cobra.Command{
 					return fmt.Errorf("failed to parse Chart.yaml at %s", path.Join(pth, "Chart.yaml"))
 				}
 
-				charts, err := LocalChartsFuture.Get()
-				if err != nil {
-					return fmt.Errorf("failed to get local charts: %v", err)
-				}
-
-				existing := types.HelmChartMultiArray(charts).FindChart(chart.RepoName).FindVersion(chart.Version)
-				if existing.Version != "" {
-					return fmt.Errorf("chart already added to manifest")
+				// the version in Chart.yaml is free to change, so only the path identifies a local chart
+				abs := filepath.Clean(utils.MergeRelativePath(Args.Context, pth))
+				for _, existing := range Manifest.LocalCharts {
+					if filepath.Clean(utils.MergeRelativePath(Args.Context, existing.String())) == abs {
+						return fmt.Errorf("chart already added to manifest")
+					}
 				}
 
 				return nil
@@ -615,6 +664,12 @@ var addLocalChartCmd = &cobra.Command{
 			logger.Info("Dry run mode, not writing manifest")
 		}
 
+		Audit(types.AuditEntry{
+			Action: types.AuditActionAdd,
+			Kind:   "local-chart",
+			Name:   Args.File,
+		}, nil)
+
 		logger.Infof("Added local chart \"%s\" to the manifest", color.GreenString(Args.File))
 	},
 }
@@ -5,6 +5,8 @@ type args struct {
 	EnvFile string
 
 	Name      string
+	Names     []string // names given for commands working on several entries at once
+	Selector  string   // selects the entries instead of names, like "namespace=db,chart!=redis"
 	Namespace string
 	File      string
 
@@ -22,12 +24,16 @@ type args struct {
 	}
 
 	UpdateCmd struct {
+		All     bool
 		List    bool
 		Version string
 	}
 
 	ImportCmd struct {
-		All bool
+		All      bool
+		Kind     string
+		Resource string
+		Selector string
 	}
 
 	Confirm bool // confirm before executing
@@ -37,10 +43,39 @@ type args struct {
 	DryRun  bool
 
 	DeployCmd struct {
-		All bool
+		All             bool
+		Since           string
+		AcceptNewDigest bool
 	}
 
+	ExportCmd struct {
+		Out        string
+		Secrets    string
+		SecretName string
+		Interval   string
+		Project    string
+		Server     string
+	}
+
+	RollbackCmd struct {
+		Revision string
+	}
+
+	HistoryCmd struct {
+		Limit int
+	}
+
+	LockWait string // how long to wait for the cluster lock, overrides the manifest
+
+	Output string // output format, text or json
+
+	Jobs int // how many charts to fetch at the same time
+
 	Debug          bool
+	Verbose        int    // -v for debug messages and external commands with their output, -vv for the time and caller as well
+	LogFile        string // file to append json logs to
+	ShowSecrets    bool   // do not mask the values of env variables in the output
+	Answers        string // yaml file answering the prompts, keyed by argument name
 	NonInteractive bool
 }
 
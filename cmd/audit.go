@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"os"
+	"os/user"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/redact"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+)
+
+func AuditPath() string {
+	return path.Join(Args.Context, "history.jsonl")
+}
+
+var auditUserFuture = types.FutureFromFunc(func() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+
+	return utils.OrStr(os.Getenv("USER"), os.Getenv("USERNAME"))
+})
+
+type gitState struct {
+	Commit string
+	Dirty  bool
+}
+
+var auditGitFuture = types.FutureFromFunc(func() gitState {
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	commit, err := external.Git.HeadCommit(ctx, Args.Context)
+	if err != nil {
+		// the context is not a git repository
+		return gitState{}
+	}
+
+	dirty, _ := external.Git.IsDirty(ctx, Args.Context)
+
+	return gitState{
+		Commit: commit,
+		Dirty:  dirty,
+	}
+})
+
+func ValuesHash(values []byte) string {
+	return "sha256:" + hex.EncodeToString(utils.Sum256(values))
+}
+
+// Audit appends an entry to the audit log in the context, filling in who, when and where.
+// Dry runs do not change anything so they are never recorded.
+func Audit(entry types.AuditEntry, err error) {
+	if Args.DryRun {
+		return
+	}
+
+	git := auditGitFuture.GetOrPanic()
+	kubeContext, _ := KubeContextFuture.Get()
+
+	entry.Time = time.Now().UTC()
+	entry.User = auditUserFuture.GetOrPanic()
+	entry.Command = currentCommand().CommandPath()
+	entry.GitCommit = git.Commit
+	entry.GitDirty = git.Dirty
+	entry.KubeContext = kubeContext
+
+	if err != nil {
+		entry.Result = types.AuditResultFailure
+		// only the first line, the rest is usually the full output of helm or kubectl
+		entry.Error = redact.String(strings.SplitN(err.Error(), "\n", 2)[0])
+	} else if entry.Result == "" {
+		entry.Result = types.AuditResultSuccess
+	}
+
+	if err := utils.AppendAudit(AuditPath(), entry); err != nil {
+		logger.Warnf("failed to write audit log: %s", err)
+	}
+}
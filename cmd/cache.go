@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/seventv/helm-manager/v2/external"
 	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/redact"
 	"github.com/seventv/helm-manager/v2/types"
 	"github.com/seventv/helm-manager/v2/utils"
 )
@@ -17,7 +19,10 @@ var NamespaceFuture = types.FutureFromFuncErr(func() ([]string, error) {
 		FailureText:  "Failed to fetch k8s namespaces",
 	})
 
-	namespaces, err := external.Kubectl.GetNamespaces()
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	namespaces, err := external.Kubectl.GetNamespaces(ctx)
 	done(err == nil)
 
 	return namespaces, err
@@ -29,7 +34,10 @@ var HelmRepoFuture = types.FutureFromFuncErr(func() ([]types.HelmRepo, error) {
 		SuccessText:  "Fetched helm repos",
 		FailureText:  "Failed to fetch helm repos",
 	})
-	repos, err := external.Helm.ListRepos()
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	repos, err := external.Helm.ListRepos(ctx)
 	done(err == nil)
 	return repos, err
 })
@@ -40,7 +48,10 @@ var HelmReleaseFuture = types.FutureFromFuncErr(func() ([]types.HelmRelease, err
 		SuccessText:  "Fetched helm releases",
 		FailureText:  "Failed to fetch helm releases",
 	})
-	releases, err := external.Helm.ListReleases()
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	releases, err := external.Helm.ListReleases(ctx)
 	done(err == nil)
 	return releases, err
 })
@@ -56,7 +67,10 @@ var HelmChartsFuture = types.FutureFromFuncErr(func() ([]types.HelmChartMulti, e
 		SuccessText:  "Fetched helm charts",
 		FailureText:  "Failed to fetch helm charts",
 	})
-	charts, err := external.Helm.ListCharts()
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	charts, err := external.Helm.ListCharts(ctx)
 	done(err == nil)
 
 	charts = append(charts, LocalChartsFuture.GetOrPanic()...)
@@ -90,6 +104,12 @@ var LocalChartsFuture = types.FutureFromFunc(func() []types.HelmChartMulti {
 
 	charts := make([]types.HelmChartMulti, 0, len(chartMp))
 	for _, c := range chartMp {
+		// newest first like helm search, the chart itself is the newest version so releases can follow it
+		sort.SliceStable(c.Versions, func(i, j int) bool {
+			return utils.CompareVersions(c.Versions[i].Version, c.Versions[j].Version) > 0
+		})
+		c.HelmChart = types.HelmChart(c.Versions[0])
+
 		charts = append(charts, *c)
 	}
 
@@ -97,16 +117,31 @@ var LocalChartsFuture = types.FutureFromFunc(func() []types.HelmChartMulti {
 })
 
 var UpdateHelmRepoFuture = types.FutureFromFuncErr(func() (bool, error) {
+	// completion lists the charts from the repo index helm already has, updating the repos on every tab press is too slow
+	if shellCompletion {
+		return true, nil
+	}
+
 	done := utils.Loader(utils.LoaderOptions{
 		FetchingText: "Updating helm repos",
 		SuccessText:  "Updated helm repos",
 		FailureText:  "Failed to update helm repos",
 	})
-	_, err := external.Helm.UpdateRepos()
+	ctx, cancel := OperationContext(types.TimeoutRepo)
+	defer cancel()
+
+	_, err := external.Helm.UpdateRepos(ctx)
 	done(err == nil)
 	return err == nil, err
 })
 
+var KubeContextFuture = types.FutureFromFuncErr(func() (string, error) {
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	return external.Kubectl.GetCurrentContext(ctx)
+})
+
 type HelmReleaseChart struct {
 	types.HelmRelease
 	Chart types.HelmChartMulti
@@ -179,6 +214,13 @@ var EnvMapFuture = types.FutureFromFunc(func() map[string]string {
 	delete(allowedEnvMp, "HELM_MANAGER_NAME")
 	delete(allowedEnvMp, "HELM_MANAGER_CONTEXT_NAME")
 
+	// the env variables are substituted into values, so any of them may be a secret
+	for env, value := range envMap {
+		if env != "HELM_MANAGER_NAME" && env != "HELM_MANAGER_CONTEXT_NAME" {
+			redact.Add(value)
+		}
+	}
+
 	for env, unused := range allowedEnvMp {
 		if unused {
 			logger.Fatalf("Env variable %s is not specified.", strings.ToUpper(env))
@@ -191,7 +233,7 @@ var EnvMapFuture = types.FutureFromFunc(func() map[string]string {
 		logger.Warn("Manifest name is not specified in manifest file this is not recommended.")
 	}
 
-	context, err := external.Kubectl.GetCurrentContext()
+	context, err := KubeContextFuture.Get()
 	if err != nil {
 		logger.Fatalf("Failed to get current kubectl context: %v", err)
 	}
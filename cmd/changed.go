@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func init() {
+	deployCmd.AddCommand(deployChangedCmd)
+	deployChangedCmd.Flags().StringVar(&Args.DeployCmd.Since, "since", "", "Git ref to compare against")
+	deployChangedCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Force deploy")
+	deployChangedCmd.Flags().BoolVarP(&Args.DeployCmd.AcceptNewDigest, "accept-new-digest", "", false, "Re-lock charts whose archive digest or signer changed")
+}
+
+// absPath resolves symlinks in the directory of a file, the file itself might not exist anymore.
+func absPath(pth string) string {
+	dir, err := filepath.Abs(filepath.Dir(pth))
+	if err != nil {
+		return filepath.Clean(pth)
+	}
+
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	return filepath.Join(dir, filepath.Base(pth))
+}
+
+type changeSet struct {
+	files map[string]bool
+	old   types.Manifest
+}
+
+func (c changeSet) fileChanged(pth string) bool {
+	return c.files[absPath(pth)]
+}
+
+func (c changeSet) dirChanged(dir string) bool {
+	dir = absPath(dir) + string(filepath.Separator)
+	for file := range c.files {
+		if strings.HasPrefix(file, dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c changeSet) releaseChanged(release types.ManifestRelease) bool {
+	old, idx := c.old.ReleaseIdxByName(release.Name)
+	if idx == -1 || !reflect.DeepEqual(old, release) || c.fileChanged(ReleasePath(release.Name)) {
+		return true
+	}
+
+	if release.Chart.Repo != "" {
+		return c.old.RepoByName(release.Chart.Repo) != Manifest.RepoByName(release.Chart.Repo)
+	}
+
+	for _, chart := range LocalChartsFuture.GetOrPanic() {
+		if chart.RepoName != release.Chart.Name {
+			continue
+		}
+
+		for _, version := range chart.Versions {
+			if c.dirChanged(version.LocalPath) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (c changeSet) singleChanged(single types.ManifestSingle) bool {
+	old := c.old.SingleByName(single.Name)
+	return old.Name == "" || !reflect.DeepEqual(old, single) || c.fileChanged(SinglePath(single.Name))
+}
+
+// ChangedEntries returns every release and single which changed since the git ref, along with everything that depends on them, in deploy order.
+func ChangedEntries(since string) ([]types.ManifestEntry, error) {
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	if err := external.Git.VerifyRef(ctx, Args.Context, since); err != nil {
+		return nil, err
+	}
+
+	top, err := external.Git.TopLevel(ctx, Args.Context)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not inside a git repository", Args.Context)
+	}
+
+	files, err := external.Git.ChangedFiles(ctx, Args.Context, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %v", err)
+	}
+
+	changes := changeSet{files: map[string]bool{}}
+	for _, file := range files {
+		changes.files[filepath.Join(top, filepath.FromSlash(file))] = true
+	}
+
+	data, ok, err := external.Git.Show(ctx, Args.Context, since, "manifest.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest at %s: %v", since, err)
+	}
+
+	if ok {
+		if changes.old, err = utils.ParseManifest(data); err != nil {
+			return nil, fmt.Errorf("failed to read manifest at %s: %v", since, err)
+		}
+	}
+
+	for _, release := range changes.old.Releases {
+		if _, idx := Manifest.ReleaseIdxByName(release.Name); idx == -1 {
+			logger.Warnf("release %s was removed from the manifest since %s, it is not uninstalled by deploy", release.Name, since)
+		}
+	}
+
+	for _, single := range changes.old.Singles {
+		if Manifest.SingleByName(single.Name).Name == "" {
+			logger.Warnf("single %s was removed from the manifest since %s, it is not uninstalled by deploy", single.Name, since)
+		}
+	}
+
+	changed := []types.ManifestEntry{}
+	selected := map[string]bool{}
+	for _, entry := range Manifest.Entries() {
+		if (entry.Kind == types.EntryKindRelease && changes.releaseChanged(entry.Release)) || (entry.Kind == types.EntryKindSingle && changes.singleChanged(entry.Single)) {
+			changed = append(changed, entry)
+			selected[entry.Key()] = true
+		}
+	}
+
+	for _, entry := range Manifest.Dependents(changed) {
+		selected[entry.Key()] = true
+	}
+
+	entries := []types.ManifestEntry{}
+	for _, entry := range Manifest.Entries() {
+		if selected[entry.Key()] {
+			entries = append(entries, entry)
+		}
+	}
+
+	return types.SortEntries(entries)
+}
+
+var deployChangedCmd = &cobra.Command{
+	Use:     "changed",
+	Short:   "Deploy releases and singles changed since a git ref",
+	Long:    "Deploy releases and singles changed since a git ref, including uncommitted and untracked changes, along with everything that depends on them",
+	Example: "   helm-manager deploy changed --since origin/main\n   helm-manager deploy changed HEAD~1",
+	Args: ui.PositionalArgs([]ui.RequiredArg{
+		ui.Arg[string]{
+			Name:       "since",
+			Ptr:        &Args.DeployCmd.Since,
+			Positional: true,
+			Validator:  types.EmptyValidator[string]("git ref", false),
+			UI:         ui.PromptUiFunc[string]("Git ref"),
+		},
+	}, func(cmd *cobra.Command) {
+		zap.S().Infof("* %s *", color.BlueString("Helm Manager Deploy Changed"))
+		ManifestExist(cmd)
+	}),
+	Run: func(cmd *cobra.Command, _ []string) {
+		entries, err := ChangedEntries(Args.DeployCmd.Since)
+		if err != nil {
+			logger.Fatalf("failed to find changes: %s", err)
+		}
+
+		if len(entries) == 0 {
+			logger.Infof("nothing changed since %s", Args.DeployCmd.Since)
+			return
+		}
+
+		for _, entry := range entries {
+			logger.Infof("%s changed", entry)
+		}
+
+		deployReposIfNeeded()
+
+		charts := []types.HelmChartMulti{}
+		for _, entry := range entries {
+			if entry.Kind == types.EntryKindRelease {
+				if charts, err = DeployChartsFuture.Get(); err != nil {
+					logger.Fatalf("failed to get helm charts: %s", err)
+				}
+
+				break
+			}
+		}
+
+		for _, entry := range entries {
+			if err := deployEntryHelper(charts, entry); err != nil {
+				logger.Fatalf("failed to deploy %s: %s", entry, err)
+			}
+		}
+
+		logger.Infof("changes deployed")
+	},
+}
@@ -9,12 +9,20 @@ import (
 	"github.com/fatih/color"
 	"github.com/seventv/helm-manager/v2/external"
 	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/redact"
 	"github.com/seventv/helm-manager/v2/types"
 	"github.com/seventv/helm-manager/v2/utils"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
+// AnnotationOffline marks commands which only work on the files in the context, they never talk to the cluster and do not need the env variables.
+const AnnotationOffline = "helm-manager/offline"
+
+func IsOffline(cmd *cobra.Command) bool {
+	return cmd.Annotations[AnnotationOffline] == "true"
+}
+
 func ManifestExist(cmd *cobra.Command) {
 	if !Manifest.Exists {
 		logger.LoggerRewrite()
@@ -24,6 +32,7 @@ func ManifestExist(cmd *cobra.Command) {
 
 type UpgradeResult struct {
 	Document          []byte
+	Values            []byte // the full values without comments, before the env variables are substituted
 	EnvSubbedValues   []byte
 	EnvSubbedDocument *yaml.Node
 	NewLock           types.ReleaseLock
@@ -46,7 +55,6 @@ func UpgradeDocument(data []byte, chart types.HelmChartMulti, ticker bool) (*Upg
 	}
 
 	const (
-		HEAD_COMMENT_LOCK   = "## This section is automatically generated by helm-manager. DO NOT EDIT.\n\n"
 		HEAD_COMMENT_VALUES = "## This section contains the non-default values for this chart.\n## If you want to change a value, add it here.\n## If you want to reset a value to default, remove it here.\n## If you want to reset all values to default, delete this entire section.\n## You can also modify the section below, any changes there will be reset however they will be copied into this section.\n\n"
 	)
 
@@ -97,7 +105,7 @@ func UpgradeDocument(data []byte, chart types.HelmChartMulti, ticker bool) (*Upg
 		done = func(bool) {}
 	}
 
-	node, err := external.Helm.GetDefaultChartValues(chart.HelmChart)
+	node, err := ChartDefaultValues(chart.HelmChart)
 	if err != nil {
 		done(false)
 		return nil, fmt.Errorf("Failed to fetch default values, %v", err)
@@ -111,7 +119,7 @@ func UpgradeDocument(data []byte, chart types.HelmChartMulti, ticker bool) (*Upg
 			// we dont have version history for this chart
 			document.Content[DEFAULTS_IDX] = defaultValues
 		} else {
-			node, err := external.Helm.GetDefaultChartValues(types.HelmChart(oldVersion))
+			node, err := ChartDefaultValues(types.HelmChart(oldVersion))
 			if err != nil {
 				done(false)
 				return nil, fmt.Errorf("Failed to parse old default values, %v", err)
@@ -136,7 +144,7 @@ func UpgradeDocument(data []byte, chart types.HelmChartMulti, ticker bool) (*Upg
 		document.Content[DEFAULTS_IDX] = defaultValues
 	}
 
-	var envSubbedChartValuesData []byte
+	var values, envSubbedChartValuesData []byte
 	{
 		// remove all comments from the full version
 		envSubbedChartValuesData, err = utils.MarshalYaml(utils.ToDocument(utils.RemoveYamlComments(utils.MergeYaml(document.Content[DEFAULTS_IDX], document.Content[VALUES_IDX]))))
@@ -145,6 +153,8 @@ func UpgradeDocument(data []byte, chart types.HelmChartMulti, ticker bool) (*Upg
 			return nil, fmt.Errorf("Failed to marshal values, %v", err)
 		}
 
+		values = envSubbedChartValuesData
+
 		// substitute the env variables into the full version without comments
 		for env, value := range envMap {
 			envSubbedChartValuesData = bytes.ReplaceAll(envSubbedChartValuesData, []byte(fmt.Sprintf("${%s}", env)), []byte(value))
@@ -162,22 +172,16 @@ func UpgradeDocument(data []byte, chart types.HelmChartMulti, ticker bool) (*Upg
 		Version: chart.Version,
 	}
 
-	{
-		lockData, err := yaml.Marshal(newLock)
-		if err != nil {
-			done(false)
-			return nil, fmt.Errorf("Failed to marshal lock, %v", err)
-		}
-
-		lockNode := &yaml.Node{}
-		if err := yaml.Unmarshal(lockData, lockNode); err != nil {
-			done(false)
-			return nil, fmt.Errorf("Failed to unmarshal lock, %v", err)
-		}
+	if oldLock.Chart == newLock.Chart && oldLock.Version == newLock.Version {
+		// only deploys record a new digest, see LockChart
+		newLock.Digest = oldLock.Digest
+		newLock.Signer = oldLock.Signer
+	}
 
-		lockNode = utils.ConvertDocument(lockNode)
-		lockNode.HeadComment = HEAD_COMMENT_LOCK
-		document.Content[LOCK_IDX] = lockNode
+	document.Content[LOCK_IDX], err = lockNode(newLock)
+	if err != nil {
+		done(false)
+		return nil, err
 	}
 
 	documentData, err := utils.MarshalYaml(&document)
@@ -192,11 +196,31 @@ func UpgradeDocument(data []byte, chart types.HelmChartMulti, ticker bool) (*Upg
 		NewLock:           newLock,
 		OldLock:           oldLock,
 		Document:          documentData,
+		Values:            values,
 		EnvSubbedValues:   envSubbedChartValuesData,
 		EnvSubbedDocument: utils.ConvertDocument(subbedDocument),
 	}, nil
 }
 
+const HEAD_COMMENT_LOCK = "## This section is automatically generated by helm-manager. DO NOT EDIT.\n\n"
+
+func lockNode(lock types.ReleaseLock) (*yaml.Node, error) {
+	lockData, err := yaml.Marshal(lock)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshal lock, %v", err)
+	}
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(lockData, node); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal lock, %v", err)
+	}
+
+	node = utils.ConvertDocument(node)
+	node.HeadComment = HEAD_COMMENT_LOCK
+
+	return node, nil
+}
+
 func ReleasePath(name string) string {
 	if name == "" {
 		panic("Release name cannot be empty")
@@ -213,73 +237,185 @@ func SinglePath(name string) string {
 	return path.Join(Args.Context, "singles", strings.ToLower(name)+".yaml")
 }
 
-func DeployRelease(release types.ManifestRelease, chart types.HelmChart, values []byte, envSubbedDocument *yaml.Node) error {
-	if !Args.Force {
-		releases, err := HelmReleaseFuture.Get()
-		if err != nil {
-			return fmt.Errorf("Failed to get releases, %v", err)
-		}
+func findDeployedRelease(release types.ManifestRelease) (types.HelmRelease, error) {
+	releases, err := HelmReleaseFuture.Get()
+	if err != nil {
+		return types.HelmRelease{}, fmt.Errorf("Failed to get releases, %v", err)
+	}
 
-		var deployedRelease types.HelmRelease
-		for _, r := range releases {
-			if r.Name == release.Name {
-				if r.Namespace == release.Namespace {
-					deployedRelease = r
-					break
-				}
-			}
-		}
-		if deployedRelease.Name != "" {
-			// check if the release is already deployed
-			deployed, err := external.Helm.GetDeployedReleaseValues(deployedRelease)
-			if err != nil {
-				logger.Debug("Failed to get deployed values for release \"%s\", %v", deployedRelease.Name, err)
-			} else {
-				if !utils.IsDifferent(utils.ConvertDocument(deployed), utils.ConvertDocument(envSubbedDocument)) {
-					logger.Infof("Release (%s) already deployed not redeploying, use --force to override", deployedRelease.Name)
-					return nil
-				}
-			}
+	for _, r := range releases {
+		if r.Name == release.Name && r.Namespace == release.Namespace {
+			return r, nil
 		}
 	}
 
-	if Args.DryRun {
-		logger.Info("Dry run, not actually deploying release")
+	return types.HelmRelease{}, nil
+}
+
+// buildChartDependencies fetches the dependencies of local charts, which are not part of the chart directory.
+func buildChartDependencies(chart types.HelmChart) error {
+	if !chart.IsLocal || isVendored(chart) || !utils.LocalChartHasDependencies(chart.LocalPath) {
+		return nil
 	}
 
 	done := utils.Loader(utils.LoaderOptions{
-		FetchingText: fmt.Sprintf("Deploying release %s", release.Name),
-		SuccessText:  fmt.Sprintf("Deployed release %s", release.Name),
-		FailureText:  fmt.Sprintf("Failed to deploy release %s", release.Name),
+		FetchingText: fmt.Sprintf("Building dependencies of chart %s", chart.RepoName),
+		SuccessText:  fmt.Sprintf("Built dependencies of chart %s", chart.RepoName),
+		FailureText:  fmt.Sprintf("Failed to build dependencies of chart %s", chart.RepoName),
 	})
 
-	resp, err := external.Helm.UpgradeRelease(release, chart, values, Args.DryRun, Args.Force)
+	ctx, cancel := OperationContext(types.TimeoutRepo)
+	defer cancel()
+
+	resp, err := external.Helm.BuildDependencies(ctx, chart.LocalPath)
 	done(err == nil)
 	if err != nil {
-		return fmt.Errorf("failed to execute helm upgrade command: %v\n%s\nFailed to deploy release\n   to try again run `%s`", color.YellowString("helm-manager deploy release %s", release.Name), err, resp)
+		return fmt.Errorf("failed to build chart dependencies: %v\n%s", err, resp)
 	}
 
 	return nil
 }
 
+func DeployRelease(release types.ManifestRelease, chart types.HelmChart, values []byte, envSubbedDocument *yaml.Node) error {
+	if err := LockCluster(); err != nil {
+		return err
+	}
+
+	if _, err := ApplyNamespacesFuture.Get(); err != nil {
+		return err
+	}
+
+	deployedRelease, err := findDeployedRelease(release)
+	if err != nil && !Args.Force {
+		return err
+	}
+
+	entry := types.AuditEntry{
+		Action:     types.AuditActionDeploy,
+		Kind:       "release",
+		Name:       release.Name,
+		Namespace:  release.Namespace,
+		OldVersion: deployedRelease.Version(),
+		NewVersion: chart.Version,
+		ValuesHash: ValuesHash(values),
+	}
+
+	if !Args.Force && deployedRelease.Name != "" {
+		// check if the release is already deployed
+		ctx, cancel := OperationContext(types.TimeoutQuery)
+		defer cancel()
+
+		deployed, err := external.Helm.GetDeployedReleaseValues(ctx, deployedRelease)
+		if err != nil {
+			logger.Debugf("Failed to get deployed values for release \"%s\", %v", deployedRelease.Name, err)
+		} else {
+			if deployedRelease.Version() == chart.Version && !utils.IsDifferent(utils.ConvertDocument(deployed), utils.ConvertDocument(envSubbedDocument)) {
+				logger.Infof("Release (%s) already deployed not redeploying, use --force to override", deployedRelease.Name)
+				entry.Result = types.AuditResultUnchanged
+				Audit(entry, nil)
+				return nil
+			}
+		}
+	}
+
+	if Args.DryRun {
+		logger.Info("Dry run, not actually deploying release")
+	}
+
+	hctx := HookContext{
+		Kind:         "release",
+		Name:         release.Name,
+		Namespace:    release.Namespace,
+		ChartVersion: chart.Version,
+		Values:       values,
+	}
+
+	err = buildChartDependencies(chart)
+	if err == nil {
+		err = checkReleasePolicy(release, chart, values)
+	}
+
+	if err != nil {
+		Audit(entry, err)
+		return err
+	}
+
+	err = WithHooks(release.Hooks, types.HookPreDeploy, types.HookPostDeploy, hctx, func() error {
+		done := utils.Loader(utils.LoaderOptions{
+			FetchingText: fmt.Sprintf("Deploying release %s", release.Name),
+			SuccessText:  fmt.Sprintf("Deployed release %s", release.Name),
+			FailureText:  fmt.Sprintf("Failed to deploy release %s", release.Name),
+		})
+
+		ctx, cancel := ReleaseContext(release)
+		defer cancel()
+
+		resp, err := external.Helm.UpgradeRelease(ctx, release, chart, values, Args.DryRun, Args.Force)
+		done(err == nil)
+		if err != nil {
+			// helm and kubectl echo the rendered manifests, which contain the substituted secrets
+			return redact.Error(fmt.Errorf("failed to execute helm upgrade command: %v\n%s\nFailed to deploy release\n   to try again run `%s`", err, resp, color.YellowString("helm-manager deploy release %s", release.Name)))
+		}
+
+		return nil
+	})
+
+	Audit(entry, err)
+
+	return err
+}
+
 func DeploySingle(single types.ManifestSingle, values []byte) error {
+	if err := LockCluster(); err != nil {
+		return err
+	}
+
+	if _, err := ApplyNamespacesFuture.Get(); err != nil {
+		return err
+	}
+
 	envMap := EnvMapFuture.GetOrPanic()
 
 	for env, value := range envMap {
 		values = bytes.ReplaceAll(values, []byte(fmt.Sprintf("${%s}", env)), []byte(value))
 	}
 
-	done := utils.Loader(utils.LoaderOptions{
-		FetchingText: fmt.Sprintf("Deploying single %s", single.Name),
-		SuccessText:  fmt.Sprintf("Deployed single %s", single.Name),
-		FailureText:  fmt.Sprintf("Failed to deploy single %s", single.Name),
-	})
+	hctx := HookContext{
+		Kind:      "single",
+		Name:      single.Name,
+		Namespace: single.Namespace,
+		Values:    values,
+	}
 
-	resp, err := external.Kubectl.Deploy(values, Args.Namespace, Args.AddSingleCmd.Create, Args.DryRun)
-	done(err == nil)
-	if err != nil {
-		return fmt.Errorf("Failed to deploy single: %v\n%s", err, resp)
+	err := checkSinglePolicy(single, values)
+	if err == nil {
+		err = WithHooks(single.Hooks, types.HookPreDeploy, types.HookPostDeploy, hctx, func() error {
+			done := utils.Loader(utils.LoaderOptions{
+				FetchingText: fmt.Sprintf("Deploying single %s", single.Name),
+				SuccessText:  fmt.Sprintf("Deployed single %s", single.Name),
+				FailureText:  fmt.Sprintf("Failed to deploy single %s", single.Name),
+			})
+
+			ctx, cancel := OperationContext(types.TimeoutDeploy)
+			defer cancel()
+
+			resp, err := external.Kubectl.Deploy(ctx, values, Args.Namespace, Args.AddSingleCmd.Create, Args.DryRun)
+			done(err == nil)
+			if err != nil {
+				return redact.Error(fmt.Errorf("Failed to deploy single: %v\n%s", err, resp))
+			}
+
+			return nil
+		})
 	}
 
-	return nil
+	Audit(types.AuditEntry{
+		Action:     types.AuditActionDeploy,
+		Kind:       "single",
+		Name:       single.Name,
+		Namespace:  single.Namespace,
+		ValuesHash: ValuesHash(values),
+	}, err)
+
+	return err
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+)
+
+// shellCompletion is set while the shell asks for completions, the futures then avoid anything slow.
+var shellCompletion bool
+
+var prepareCompletionOnce sync.Once
+
+// completing reports if the shell asks for completions. Cobra adds the command answering it only once it executes,
+// and it parses the flags of the completed command after the initializers ran.
+func completing() bool {
+	return len(os.Args) > 1 && (os.Args[1] == cobra.ShellCompRequestCmd || os.Args[1] == cobra.ShellCompNoDescRequestCmd)
+}
+
+// isCompletionScript reports if cmd prints a completion script, which works without a context.
+func isCompletionScript(cmd *cobra.Command) bool {
+	return cmd.HasParent() && cmd.Parent().Name() == "completion" && cmd.Parent().Parent() == cmd.Root()
+}
+
+// prepareCompletion reads the manifest from the context given to the completed command.
+// Nothing may be logged, the shell reads the completions from stdout.
+func prepareCompletion() {
+	prepareCompletionOnce.Do(func() {
+		shellCompletion = true
+		logger.Redirect(io.Discard)
+
+		wd, _ := os.Getwd()
+		Args.Context = utils.MergeRelativePath(wd, Args.Context)
+
+		if err := utils.ReadManifest(Args.Context); err != nil {
+			ManifestErr = err
+		}
+	})
+}
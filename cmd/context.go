@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"go.uber.org/zap"
+)
+
+// RootContext is cancelled when helm-manager receives SIGINT or SIGTERM, every external command runs under it.
+var RootContext, cancelRootContext = context.WithCancel(context.Background())
+
+// OperationContext bounds a single external command by the timeout the manifest configures for the operation.
+func OperationContext(op types.TimeoutOperation) (context.Context, context.CancelFunc) {
+	timeout, err := Manifest.Timeouts.Duration(op)
+	if err != nil {
+		timeout = types.DefaultTimeouts[op]
+	}
+
+	return context.WithTimeout(RootContext, timeout)
+}
+
+// ReleaseContext is OperationContext for changing a release in the cluster, which can have its own timeout.
+func ReleaseContext(release types.ManifestRelease) (context.Context, context.CancelFunc) {
+	timeout, err := release.DeployTimeout(Manifest.Timeouts)
+	if err != nil {
+		timeout = types.DefaultTimeouts[types.TimeoutDeploy]
+	}
+
+	return context.WithTimeout(RootContext, timeout)
+}
+
+// cleanupContext is used to undo things after RootContext was cancelled, such as releasing the cluster lock.
+func cleanupContext() (context.Context, context.CancelFunc) {
+	timeout, err := Manifest.Timeouts.Duration(types.TimeoutQuery)
+	if err != nil {
+		timeout = types.DefaultTimeouts[types.TimeoutQuery]
+	}
+
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// handleSignals cancels RootContext on the first SIGINT or SIGTERM, so running commands can stop cleanly.
+// A second signal exits straight away.
+func handleSignals() {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-sigs
+
+		logger.LoggerRewrite()
+		logger.Warnf("Received %s, stopping running commands, send it again to exit immediately", sig)
+		cancelRootContext()
+
+		<-sigs
+		logger.RunExitHooks()
+		os.Exit(130)
+	}()
+}
+
+// printStoppedCommands reports the commands which did not finish, so nobody has to guess what state the cluster was left in.
+func printStoppedCommands() {
+	stopped := utils.StoppedCommands()
+	if len(stopped) == 0 {
+		return
+	}
+
+	if RootContext.Err() != nil {
+		logger.Warnf("Interrupted, %d commands did not finish:", len(stopped))
+	} else {
+		logger.Warnf("%d commands did not finish:", len(stopped))
+	}
+
+	helmChanged := false
+	for _, cmd := range stopped {
+		reason := "cancelled"
+		if cmd.TimedOut {
+			reason = "timed out"
+		}
+
+		zap.S().Infof("    %s %s", color.YellowString(reason), cmd.Command)
+
+		for _, verb := range []string{"helm upgrade", "helm uninstall", "helm rollback"} {
+			helmChanged = helmChanged || strings.HasPrefix(cmd.Command, verb)
+		}
+	}
+
+	if helmChanged {
+		logger.Warnf("releases may be left pending, check them with `%s` before deploying again", color.YellowString("helm-manager status"))
+	}
+}
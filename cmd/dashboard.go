@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(dashboardCmd)
+}
+
+// lastDeploys maps "kind/name" to the newest deploy in the audit log.
+func lastDeploys() map[string]types.AuditEntry {
+	entries, err := utils.ReadAudit(AuditPath())
+	if err != nil {
+		logger.Warnf("failed to read audit log: %s", err)
+	}
+
+	deploys := map[string]types.AuditEntry{}
+	for _, entry := range entries {
+		if entry.Action == types.AuditActionDeploy {
+			deploys[entry.Kind+"/"+strings.ToLower(entry.Name)] = entry
+		}
+	}
+
+	return deploys
+}
+
+func lastDeployColumn(deploys map[string]types.AuditEntry, kind string, name string) string {
+	entry, ok := deploys[kind+"/"+strings.ToLower(name)]
+	if !ok {
+		return "-"
+	}
+
+	when := entry.Time.Local().Format("2006-01-02 15:04")
+	if entry.Result == types.AuditResultFailure {
+		return color.RedString("%s failed", when)
+	}
+
+	return when
+}
+
+// availableUpdate is the newest version of the chart of a release, if it is newer than the one in the manifest.
+func availableUpdate(release types.ManifestRelease) string {
+	charts, err := HelmChartsFuture.Get()
+	if err != nil {
+		return ""
+	}
+
+	chart := types.HelmChartMultiArray(charts).FindChart(release.Chart.RepoName())
+	if chart.RepoName == "" || utils.CompareVersions(chart.Version, release.Chart.Version) <= 0 {
+		return ""
+	}
+
+	return chart.Version
+}
+
+// dashboardRows reloads the manifest and the cluster state, the actions run as separate processes which may have changed both.
+func dashboardRows() ([]ui.DashboardRow, error) {
+	*Manifest = types.Manifest{}
+	if err := utils.ReadManifest(Args.Context); err != nil {
+		return nil, err
+	}
+
+	HelmReleaseFuture.Reset()
+	types.Prefetch(HelmReleaseFuture, HelmChartsFuture)
+
+	deploys := lastDeploys()
+	rows := make([]ui.DashboardRow, 0, len(Manifest.Releases)+len(Manifest.Singles))
+
+	for _, release := range Manifest.Releases {
+		status, err := releaseStatus(release)
+		if err != nil {
+			return nil, err
+		}
+
+		state := statusState(status.Drift)
+		if status.Status != "deployed" {
+			state = color.YellowString(status.Status)
+		}
+
+		rows = append(rows, ui.DashboardRow{
+			Item: release,
+			Columns: []string{
+				"release",
+				state,
+				release.Chart.Version,
+				color.GreenString(availableUpdate(release)),
+				lastDeployColumn(deploys, "release", release.Name),
+			},
+		})
+	}
+
+	for _, single := range Manifest.Singles {
+		rows = append(rows, ui.DashboardRow{
+			Item: single,
+			Columns: []string{
+				"single",
+				"-",
+				"-",
+				"",
+				lastDeployColumn(deploys, "single", single.Name),
+			},
+		})
+	}
+
+	return rows, nil
+}
+
+// runSelf runs a helm-manager command against the same context, its output goes to the log pane of the dashboard.
+func runSelf(log io.Writer, args ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args = append([]string{"--context", Args.Context, "--env", Args.EnvFile, "--term"}, args...)
+	if Args.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if Args.LockWait != "" {
+		args = append(args, "--lock-wait", Args.LockWait)
+	}
+	if Args.LogFile != "" {
+		args = append(args, "--log-file", Args.LogFile)
+	}
+	if Args.ShowSecrets {
+		args = append(args, "--show-secrets")
+	}
+
+	return utils.ExecuteCommandWriter(RootContext, log, exe, args...)
+}
+
+func releaseOnly(item types.Selectable, action string) (types.ManifestRelease, error) {
+	release, ok := item.(types.ManifestRelease)
+	if !ok {
+		return release, fmt.Errorf("%s only works on releases", action)
+	}
+
+	return release, nil
+}
+
+var dashboardActions = []ui.DashboardAction{
+	{
+		Key:  'd',
+		Name: "diff",
+		Run: func(item types.Selectable, log io.Writer) error {
+			release, err := releaseOnly(item, "diff")
+			if err != nil {
+				return err
+			}
+
+			return runSelf(log, "diff", release.Name)
+		},
+	},
+	{
+		Key:     'u',
+		Name:    "update",
+		Confirm: "Update %s to the newest chart version?",
+		Run: func(item types.Selectable, log io.Writer) error {
+			release, err := releaseOnly(item, "update")
+			if err != nil {
+				return err
+			}
+
+			version := availableUpdate(release)
+			if version == "" {
+				return fmt.Errorf("no update available")
+			}
+
+			return runSelf(log, "update", release.Name, version)
+		},
+	},
+	{
+		Key:     'D',
+		Name:    "deploy",
+		Confirm: "Deploy %s?",
+		Run: func(item types.Selectable, log io.Writer) error {
+			if single, ok := item.(types.ManifestSingle); ok {
+				return runSelf(log, "deploy", "single", single.Name)
+			}
+
+			return runSelf(log, "deploy", "release", item.Selected())
+		},
+	},
+	{
+		Key:     'r',
+		Name:    "rollback",
+		Confirm: "Roll %s back to the previous revision?",
+		Run: func(item types.Selectable, log io.Writer) error {
+			release, err := releaseOnly(item, "rollback")
+			if err != nil {
+				return err
+			}
+
+			return runSelf(log, "rollback", release.Name, "--confirm")
+		},
+	},
+	{
+		Key:     'e',
+		Name:    "edit",
+		Suspend: true,
+		Run: func(item types.Selectable, _ io.Writer) error {
+			file := ReleasePath(item.Selected())
+			if single, ok := item.(types.ManifestSingle); ok {
+				file = SinglePath(single.Name)
+			}
+
+			editor := strings.Fields(utils.OrStr(os.Getenv("VISUAL"), utils.OrStr(os.Getenv("EDITOR"), "vi")))
+			return utils.ExecuteAttached(RootContext, editor[0], append(editor[1:], file)...)
+		},
+	},
+}
+
+var dashboardCmd = &cobra.Command{
+	Use:     "ui",
+	Short:   "Show a full screen dashboard of the releases and singles",
+	Long:    "Show a full screen dashboard of the releases and singles in the manifest with their status, and diff, update, deploy, roll back or edit them",
+	Example: "   helm-manager ui",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		ManifestExist(cmd)
+
+		if !ui.UseInteractive() {
+			logger.Fatal("the dashboard needs an interactive terminal")
+		}
+
+		dashboard := &ui.Dashboard{
+			Title:   "Helm Manager " + Manifest.Name,
+			Headers: []string{"NAME", "KIND", "STATUS", "VERSION", "UPDATE", "LAST DEPLOY"},
+			Rows:    dashboardRows,
+			Actions: dashboardActions,
+		}
+
+		if err := dashboard.Run(); err != nil {
+			logger.Fatal(err)
+		}
+	},
+}
@@ -19,15 +19,18 @@ func init() {
 
 	{
 		deployCmd.AddCommand(deployReleaseCmd)
-		deployReleaseCmd.Flags().StringVarP(&Args.Name, "name", "", "", "Release name")
+		deployReleaseCmd.Flags().StringSliceVarP(&Args.Names, "name", "", nil, "Release name, can be repeated")
+		deployReleaseCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Deploy the releases matching the selector, like tier=backend,team!=data")
 		deployReleaseCmd.Flags().BoolVarP(&Args.DeployCmd.All, "all", "", false, "Deploy all releases")
 		deployReleaseCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Force deploy")
+		deployReleaseCmd.Flags().BoolVarP(&Args.DeployCmd.AcceptNewDigest, "accept-new-digest", "", false, "Re-lock charts whose archive digest or signer changed")
 	}
 
 	{
 		deployCmd.AddCommand(deploySingleCmd)
-		deploySingleCmd.Flags().StringVarP(&Args.Name, "name", "", "", "single name")
-		deploySingleCmd.Flags().BoolVarP(&Args.DeployCmd.All, "all", "", false, "Deploy all releases")
+		deploySingleCmd.Flags().StringSliceVarP(&Args.Names, "name", "", nil, "Single name, can be repeated")
+		deploySingleCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Deploy the singles matching the selector, like tier=backend,team!=data")
+		deploySingleCmd.Flags().BoolVarP(&Args.DeployCmd.All, "all", "", false, "Deploy all singles")
 		deploySingleCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Force deploy")
 	}
 
@@ -38,6 +41,8 @@ func init() {
 	{
 		deployCmd.AddCommand(deployAllCmd)
 		deployAllCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Force deploy")
+		deployAllCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Only deploy the releases and singles matching the selector, like tier=backend,team!=data")
+		deployAllCmd.Flags().BoolVarP(&Args.DeployCmd.AcceptNewDigest, "accept-new-digest", "", false, "Re-lock charts whose archive digest or signer changed")
 	}
 }
 
@@ -73,69 +78,139 @@ func deployReleaseHelper(release types.ManifestRelease, chart types.HelmChartMul
 		return err
 	}
 
+	lockedChart, cleanup, err := LockChart(result, chart.HelmChart)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
 	if !Args.DryRun {
 		if err = os.WriteFile(ReleasePath(release.Name), result.Document, 0644); err != nil {
 			logger.Fatalf("failed to write release file: %s", err)
 		}
 	}
 
-	return DeployRelease(release, chart.HelmChart, result.EnvSubbedValues, result.EnvSubbedDocument)
+	if err := DeployRelease(release, lockedChart, result.EnvSubbedValues, result.EnvSubbedDocument); err != nil {
+		return err
+	}
+
+	trackLocalChart(release, chart.HelmChart)
+
+	return nil
+}
+
+// deployOrder orders releases and singles so each is deployed after the ones it depends on, otherwise keeping their order.
+func deployOrder(releases []types.ManifestRelease, singles []types.ManifestSingle) []types.ManifestEntry {
+	entries := make([]types.ManifestEntry, 0, len(releases)+len(singles))
+	for _, release := range releases {
+		entries = append(entries, types.ManifestEntry{Kind: types.EntryKindRelease, Release: release})
+	}
+
+	for _, single := range singles {
+		entries = append(entries, types.ManifestEntry{Kind: types.EntryKindSingle, Single: single})
+	}
+
+	entries, err := types.SortEntries(entries)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	return entries
+}
+
+func deployEntryHelper(charts []types.HelmChartMulti, entry types.ManifestEntry) error {
+	if entry.Kind == types.EntryKindRelease {
+		return deployReleaseHelper(entry.Release, releaseChart(charts, entry.Release))
+	}
+
+	return deploySingleHelper(entry.Single)
+}
+
+// prefetchReleaseValues fetches the chart values all the releases need, so deploying them one by one does not wait on the network.
+func prefetchReleaseValues(charts []types.HelmChartMulti, releases []types.ManifestRelease) {
+	needed := []types.HelmChart{}
+	for _, release := range releases {
+		// unreadable release files are reported when the release is deployed
+		data, err := utils.ReadFile(ReleasePath(release.Name))
+		if err != nil {
+			continue
+		}
+
+		needed = append(needed, UpgradeDocumentCharts(data, releaseChart(charts, release))...)
+	}
+
+	PrefetchChartValues(needed)
+}
+
+// trackLocalChart records the version of a local chart a release was deployed with in the manifest,
+// so local charts can be developed without updating the manifest by hand. Dry runs do not deploy anything to record.
+func trackLocalChart(release types.ManifestRelease, chart types.HelmChart) {
+	if Args.DryRun || !chart.IsLocal || release.Chart.Repo != "" || release.Chart.Version == chart.Version {
+		return
+	}
+
+	logger.Infof("Local chart %s of release %s moved from %s to %s", chart.RepoName, release.Name, color.RedString(release.Chart.Version), color.GreenString(chart.Version))
+
+	_, idx := Manifest.ReleaseIdxByName(release.Name)
+	if idx == -1 {
+		return
+	}
+
+	Manifest.Releases[idx].Chart.Version = chart.Version
+	Manifest.Releases[idx].Chart.AppVersion = chart.AppVersion
+	utils.WriteManifest(Args.Context)
+}
+
+// releaseChart finds the chart and version a release is pinned to, exiting if it is not available.
+// Releases using a local chart follow the newest version of the chart instead, deploying them records it with trackLocalChart.
+func releaseChart(charts []types.HelmChartMulti, release types.ManifestRelease) types.HelmChartMulti {
+	mutliChart := types.HelmChartMultiArray(charts).FindChart(release.Chart.RepoName())
+
+	version := release.Chart.Version
+	if release.Chart.Repo == "" && mutliChart.IsLocal {
+		version = mutliChart.Version
+	}
+
+	chart := mutliChart.FindVersion(version)
+	if chart.Version == "" {
+		logger.Fatalf("no version %s found for %s", version, release.Chart.RepoName())
+	}
+
+	mutliChart.HelmChart = types.HelmChart(chart)
+	return mutliChart
 }
 
 var deployReleaseCmd = &cobra.Command{
-	Use:   "release",
-	Short: "Deploy a release",
-	Long:  "Deploy a release",
+	Use:     "release",
+	Short:   "Deploy releases",
+	Long:    "Deploy releases",
+	Example: "   helm-manager deploy release [NAME...]\n   helm-manager deploy release mongo redis\n   helm-manager deploy release -l tier=backend",
 	Args: ui.PositionalArgs([]ui.RequiredArg{
-		ui.Arg[string]{
-			Name:       "Name",
-			Ptr:        &Args.Name,
-			Positional: true,
-			Disabled:   types.FutureFromPtr(&Args.DeployCmd.All),
-			Validator: types.EqualValidator(types.ToStringer("\"%s\" is not a release in the manifest"), types.FutureFromFuncErr(func() ([]string, error) {
-				names := make([]string, len(Manifest.Releases))
-				for i, r := range Manifest.Releases {
-					names[i] = r.Name
-				}
-				return names, nil
-			})),
-			UI: ui.PromptUiSelectorFunc[string]("Name", "", func(i int) error {
-				Args.Name = Manifest.Releases[i].Name
-				return nil
-			}, types.FutureInterfacerArray[types.ManifestRelease, types.Selectable](types.FutureFromPtr(&Manifest.Releases))),
-		},
+		releaseNamesArg("Which releases do you want to deploy", types.FutureFromFunc(func() bool {
+			return Args.DeployCmd.All || Args.Selector != ""
+		})),
 	}, func(cmd *cobra.Command) {
 		zap.S().Infof("* %s *", color.BlueString("Helm Manager Deploy Release"))
 	}),
 	Run: func(cmd *cobra.Command, _ []string) {
-		charts, err := HelmChartsFuture.Get()
+		types.Prefetch(HelmReleaseFuture, KubeContextFuture)
+
+		charts, err := DeployChartsFuture.Get()
 		if err != nil {
 			logger.Fatalf("failed to get helm charts: %s", err)
 		}
 
-		if Args.DeployCmd.All {
-			for _, release := range Manifest.Releases {
-				mutliChart := types.HelmChartMultiArray(charts).FindChart(release.Chart.RepoName())
-				chart := mutliChart.FindVersion(release.Chart.Version)
-				if chart.Version == "" {
-					logger.Fatalf("no version %s found for %s", release.Chart.Version, release.Chart.RepoName())
-				}
+		releases := Manifest.Releases
+		if !Args.DeployCmd.All {
+			releases = selectedReleases()
+		}
 
-				mutliChart.HelmChart = types.HelmChart(chart)
-				if err := deployReleaseHelper(release, mutliChart); err != nil {
-					logger.Fatalf("failed to deploy release: %s", err)
-				}
-			}
-		} else {
-			release := Manifest.ReleaseByName(Args.Name)
-			mutliChart := types.HelmChartMultiArray(charts).FindChart(release.Chart.RepoName())
-			chart := mutliChart.FindVersion(release.Chart.Version)
-			if chart.Version == "" {
-				logger.Fatalf("no version %s found for %s", release.Chart.Version, release.Chart.RepoName())
-			}
+		if len(releases) > 1 {
+			prefetchReleaseValues(charts, releases)
+		}
 
-			mutliChart.HelmChart = types.HelmChart(chart)
-			if err := deployReleaseHelper(release, mutliChart); err != nil {
+		for _, entry := range deployOrder(releases, nil) {
+			if err := deployEntryHelper(charts, entry); err != nil {
 				logger.Fatalf("failed to deploy release: %s", err)
 			}
 		}
@@ -164,22 +239,25 @@ func deployReposHelper() {
 		repoMp[repo.Name] = repo
 	}
 
+	ctx, cancel := OperationContext(types.TimeoutRepo)
+	defer cancel()
+
 	var resp []byte
 	for _, repo := range Manifest.Repos {
 		if loadedRepo, ok := repoMp[repo.Name]; !ok {
-			resp, err = external.Helm.AddRepo(repo)
+			resp, err = external.Helm.AddRepo(ctx, repo)
 			if err != nil {
 				logger.Fatalf("failed to add repo: %s\n%s", err, resp)
 			}
 		} else {
 			if loadedRepo.URL != repo.URL {
 				if args.Args.Force {
-					resp, err = external.Helm.RemoveRepo(loadedRepo)
+					resp, err = external.Helm.RemoveRepo(ctx, loadedRepo)
 					if err != nil {
 						logger.Fatalf("failed to remove repo: %s\n%s", err, resp)
 					}
 
-					resp, err = external.Helm.AddRepo(repo)
+					resp, err = external.Helm.AddRepo(ctx, repo)
 					if err != nil {
 						logger.Fatalf("failed to add repo: %s\n%s", err, resp)
 					}
@@ -199,41 +277,26 @@ func deployReposHelper() {
 }
 
 var deploySingleCmd = &cobra.Command{
-	Use:   "single",
-	Short: "Deploy a single",
-	Long:  "Deploy a single",
+	Use:     "single",
+	Short:   "Deploy singles",
+	Long:    "Deploy singles",
+	Example: "   helm-manager deploy single [NAME...]\n   helm-manager deploy single ingresses app-config\n   helm-manager deploy single -l tier=backend",
 	Args: ui.PositionalArgs([]ui.RequiredArg{
-		ui.Arg[string]{
-			Name:       "Name",
-			Ptr:        &Args.Name,
-			Positional: true,
-			Disabled:   types.FutureFromPtr(&Args.DeployCmd.All),
-			Validator: types.EqualValidator(types.ToStringer("\"%s\" is not a single in the manifest"), types.FutureFromFuncErr(func() ([]string, error) {
-				names := make([]string, len(Manifest.Singles))
-				for i, r := range Manifest.Releases {
-					names[i] = r.Name
-				}
-				return names, nil
-			})),
-			UI: ui.PromptUiSelectorFunc[string]("Name", "", func(i int) error {
-				Args.Name = Manifest.Singles[i].Name
-				return nil
-			}, types.FutureInterfacerArray[types.ManifestSingle, types.Selectable](types.FutureFromPtr(&Manifest.Singles))),
-		},
+		singleNamesArg("Which singles do you want to deploy", types.FutureFromFunc(func() bool {
+			return Args.DeployCmd.All || Args.Selector != ""
+		})),
 	}, func(cmd *cobra.Command) {
 		zap.S().Infof("* %s *", color.BlueString("Helm Manager Deploy Single"))
 	}),
 	Run: func(cmd *cobra.Command, _ []string) {
-		if Args.DeployCmd.All {
-			for _, single := range Manifest.Singles {
-				if err := deploySingleHelper(single); err != nil {
-					logger.Fatalf("failed to deploy release: %s", err)
-				}
-			}
-		} else {
-			single := Manifest.SingleByName(Args.Name)
-			if err := deploySingleHelper(single); err != nil {
-				logger.Fatalf("failed to deploy release: %s", err)
+		singles := Manifest.Singles
+		if !Args.DeployCmd.All {
+			singles = selectedSingles()
+		}
+
+		for _, entry := range deployOrder(nil, singles) {
+			if err := deployEntryHelper(nil, entry); err != nil {
+				logger.Fatalf("failed to deploy single: %s", err)
 			}
 		}
 
@@ -258,36 +321,36 @@ var deployRepoCmd = &cobra.Command{
 var deployAllCmd = &cobra.Command{
 	Use:   "all",
 	Short: "Deploy all releases and singles",
-	Long:  "Deploy all releases and singles",
+	Long:  "Deploy all releases and singles, each after the ones it depends on",
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, _ []string) {
 		zap.S().Infof("* %s *", color.BlueString("Helm Manager Deploy All"))
 
 		ManifestExist(cmd)
 
-		deployReposHelper()
+		releases, singles := filteredReleases(), filteredSingles()
+		if len(releases) == 0 && len(singles) == 0 {
+			logger.Fatalf("nothing matches the selector %s", Args.Selector)
+		}
 
-		charts, err := HelmChartsFuture.Get()
-		if err != nil {
-			logger.Fatalf("failed to get helm charts: %s", err)
+		// none of these depend on each other, so fetch them while the repos are deployed
+		types.Prefetch(HelmReleaseFuture, KubeContextFuture)
+		if !Manifest.Vendor {
+			types.Prefetch(HelmRepoFuture)
 		}
 
-		for _, release := range Manifest.Releases {
-			mutliChart := types.HelmChartMultiArray(charts).FindChart(release.Chart.RepoName())
-			chart := mutliChart.FindVersion(release.Chart.Version)
-			if chart.Version == "" {
-				logger.Fatalf("no version %s found for %s", release.Chart.Version, release.Chart.RepoName())
-			}
+		deployReposIfNeeded()
 
-			mutliChart.HelmChart = types.HelmChart(chart)
-			if err := deployReleaseHelper(release, mutliChart); err != nil {
-				logger.Fatalf("failed to deploy release: %s", err)
-			}
+		charts, err := DeployChartsFuture.Get()
+		if err != nil {
+			logger.Fatalf("failed to get helm charts: %s", err)
 		}
 
-		for _, single := range Manifest.Singles {
-			if err := deploySingleHelper(single); err != nil {
-				logger.Fatalf("failed to deploy release: %s", err)
+		prefetchReleaseValues(charts, releases)
+
+		for _, entry := range deployOrder(releases, singles) {
+			if err := deployEntryHelper(charts, entry); err != nil {
+				logger.Fatalf("failed to deploy %s: %s", entry, err)
 			}
 		}
 
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/redact"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringSliceVar(&Args.Names, "name", nil, "Name of the release, can be repeated")
+	diffCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Diff the releases matching the selector, like tier=backend,team!=data")
+}
+
+// decodeValues decodes values into plain maps and lists, nil values decode to nil.
+func decodeValues(node *yaml.Node) (any, error) {
+	var values any
+	if node != nil {
+		if err := node.Decode(&values); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// comparableValues marshals values with sorted keys and without comments, so only real changes show up in a diff.
+func comparableValues(values any) (string, error) {
+	if values == nil {
+		return "", nil
+	}
+
+	data, err := yaml.Marshal(values)
+	return string(data), err
+}
+
+// maskSecretValues masks every value which is set from an env variable in the release file, in both the wanted and the deployed values.
+// raw are the values before the env variables are substituted, a changed secret shows up as changed without showing either value.
+func maskSecretValues(raw any, want any, have any) (any, any) {
+	switch raw := raw.(type) {
+	case string:
+		if !envRefRegex.MatchString(raw) {
+			return want, have
+		}
+
+		if have == nil {
+			return redact.Text, nil
+		}
+
+		if !reflect.DeepEqual(want, have) {
+			return redact.Text + " (changed)", redact.Text
+		}
+
+		return redact.Text, redact.Text
+	case map[string]any:
+		wantMap, ok := want.(map[string]any)
+		if !ok {
+			return want, have
+		}
+
+		haveMap, _ := have.(map[string]any)
+		for k, v := range raw {
+			h, ok := haveMap[k]
+			wantMap[k], h = maskSecretValues(v, wantMap[k], h)
+			if ok {
+				haveMap[k] = h
+			}
+		}
+	case []any:
+		wantList, ok := want.([]any)
+		if !ok {
+			return want, have
+		}
+
+		haveList, _ := have.([]any)
+		for i, v := range raw {
+			if i >= len(wantList) {
+				break
+			}
+
+			var h any
+			if i < len(haveList) {
+				h = haveList[i]
+			}
+
+			wantList[i], h = maskSecretValues(v, wantList[i], h)
+			if i < len(haveList) {
+				haveList[i] = h
+			}
+		}
+	}
+
+	return want, have
+}
+
+// releaseDiff compares the values deployed in the cluster with the values the release file would deploy.
+func releaseDiff(release types.ManifestRelease) ([]string, error) {
+	charts, err := DeployChartsFuture.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get helm charts: %v", err)
+	}
+
+	data, err := utils.ReadFile(ReleasePath(release.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release file: %v", err)
+	}
+
+	result, err := UpgradeDocument(data, releaseChart(charts, release), false)
+	if err != nil {
+		return nil, err
+	}
+
+	wantValues, err := decodeValues(result.EnvSubbedDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawValues any
+	if redact.Enabled() {
+		if err := yaml.Unmarshal(result.Values, &rawValues); err != nil {
+			return nil, err
+		}
+	}
+
+	deployedRelease, err := findDeployedRelease(release)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{}
+	if deployedRelease.Name == "" {
+		wantValues, _ = maskSecretValues(rawValues, wantValues, nil)
+		want, err := comparableValues(wantValues)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, color.YellowString("release is not installed"))
+		return append(lines, utils.DiffHunks(utils.DiffLines("", want), 0)...), nil
+	}
+
+	if deployedRelease.Version() != release.Chart.Version {
+		lines = append(lines, fmt.Sprintf("chart version %s -> %s", color.RedString(deployedRelease.Version()), color.GreenString(release.Chart.Version)))
+	}
+
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	deployedValues, err := external.Helm.GetDeployedReleaseValues(ctx, deployedRelease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployed values: %v", err)
+	}
+
+	haveValues, err := decodeValues(deployedValues)
+	if err != nil {
+		return nil, err
+	}
+
+	wantValues, haveValues = maskSecretValues(rawValues, wantValues, haveValues)
+
+	want, err := comparableValues(wantValues)
+	if err != nil {
+		return nil, err
+	}
+
+	have, err := comparableValues(haveValues)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(lines, utils.DiffHunks(utils.DiffLines(have, want), 3)...), nil
+}
+
+var diffCmd = &cobra.Command{
+	Use:     "diff",
+	Short:   "Show what deploying a release would change",
+	Long:    "Show the difference between the values deployed in the cluster and the values in the release file",
+	Example: "   helm-manager diff [NAME...]\n   helm-manager diff mongo\n   helm-manager diff -l tier=backend" + USAGE_EXTRA,
+	Args: ui.PositionalArgs([]ui.RequiredArg{
+		releaseNamesArg("Which releases do you want to diff", selectorSet),
+	}, func(cmd *cobra.Command) {
+		zap.S().Infof("* %s *", color.CyanString("Helm Manager Diff"))
+		ManifestExist(cmd)
+	}),
+	Run: func(cmd *cobra.Command, _ []string) {
+		releases := selectedReleases()
+		if len(releases) > 1 {
+			charts, err := DeployChartsFuture.Get()
+			if err != nil {
+				logger.Fatalf("failed to get helm charts: %s", err)
+			}
+
+			prefetchReleaseValues(charts, releases)
+		}
+
+		for _, release := range releases {
+			lines, err := releaseDiff(release)
+			if err != nil {
+				logger.Fatalf("failed to diff release %s: %s", release.Name, err)
+			}
+
+			if len(lines) == 0 {
+				logger.Infof("release %s is up to date", release.Name)
+				continue
+			}
+
+			if len(releases) > 1 {
+				zap.S().Infof("* %s *", color.CyanString(release.Name))
+			}
+
+			for _, line := range lines {
+				zap.S().Info(line)
+			}
+		}
+	},
+}
@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SecretsKeep leaves ${VAR} placeholders as they are, for example for Flux post build substitution.
+	SecretsKeep = "keep"
+	// SecretsSecret reads placeholders from a secret, through valuesFrom for Flux and argocd-vault-plugin for Argo CD.
+	SecretsSecret = "secret"
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	for _, cmd := range []*cobra.Command{exportArgoCmd, exportFluxCmd} {
+		exportCmd.AddCommand(cmd)
+		cmd.Flags().StringVar(&Args.ExportCmd.Out, "out", "", "Directory to write the generated files to")
+		cmd.Flags().StringVarP(&Args.Namespace, "namespace", "n", "", "Namespace of the generated objects")
+		cmd.Flags().StringVar(&Args.ExportCmd.Secrets, "secrets", SecretsKeep, "How ${VAR} placeholders are exported, keep or secret")
+		cmd.Flags().StringVar(&Args.ExportCmd.SecretName, "secret-name", "helm-manager-env", "Secret to read placeholders from with --secrets secret")
+	}
+
+	exportArgoCmd.Flags().StringVar(&Args.ExportCmd.Project, "project", "default", "Argo CD project of the applications")
+	exportArgoCmd.Flags().StringVar(&Args.ExportCmd.Server, "server", "https://kubernetes.default.svc", "Cluster the applications are deployed to")
+	exportFluxCmd.Flags().StringVar(&Args.ExportCmd.Interval, "interval", "10m", "Reconcile interval of the generated objects")
+}
+
+var exactEnvRefRegex = regexp.MustCompile(`^\$\{([^}]*)\}$`)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the manifest to GitOps controllers",
+	Long:  "Export the manifest to Argo CD Applications or Flux HelmReleases",
+	Args:  ui.SubCommandRequired(cobra.NoArgs),
+	Run: func(cmd *cobra.Command, _ []string) {
+		zap.S().Infof("* %s *\r", color.CyanString("Helm Manager Export"))
+
+		ManifestExist(cmd)
+
+		cmds := make([]ui.SelectableCommand, 0, len(cmd.Commands()))
+		for _, cmd := range cmd.Commands() {
+			if !cmd.Hidden && cmd.Name() != "help" {
+				cmds = append(cmds, ui.CmdSelectable(cmd))
+			}
+		}
+
+		ui.RunSubCommand(cmd, cmds)
+	},
+}
+
+func exportArgs(header string) cobra.PositionalArgs {
+	return ui.PositionalArgs([]ui.RequiredArg{
+		ui.Arg[string]{
+			Name:      "out",
+			Ptr:       &Args.ExportCmd.Out,
+			Validator: types.EmptyValidator[string]("out", false),
+			UI:        ui.PromptUiFunc[string]("Output directory"),
+		},
+	}, func(cmd *cobra.Command) {
+		zap.S().Infof("* %s *", color.CyanString(header))
+		ManifestExist(cmd)
+
+		if Args.ExportCmd.Secrets != SecretsKeep && Args.ExportCmd.Secrets != SecretsSecret {
+			logger.Fatalf("unknown secrets strategy \"%s\", must be %s or %s", Args.ExportCmd.Secrets, SecretsKeep, SecretsSecret)
+		}
+	})
+}
+
+// exportValues returns the values section of a release file, the defaults are left out since the chart provides them.
+func exportValues(release types.ManifestRelease) (*yaml.Node, error) {
+	data, err := os.ReadFile(ReleasePath(release.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	if Manifest.Name != "" {
+		data = bytes.ReplaceAll(data, []byte("${HELM_MANAGER_NAME}"), []byte(Manifest.Name))
+	}
+
+	node, err := utils.ParseYaml(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var values *yaml.Node
+	switch len(node.Content) {
+	case 1:
+		values = node.Content[0]
+	case 3:
+		values = node.Content[1]
+	default:
+		return nil, fmt.Errorf("release file must contain 3 documents, found %d", len(node.Content))
+	}
+
+	if values.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("values section must be a mapping")
+	}
+
+	return values, nil
+}
+
+// walkPlaceholders calls fn for every scalar containing a placeholder with its helm --set style path.
+// Values inside lists cannot be removed without changing the list, for anything else fn can return true to remove the value from its parent.
+func walkPlaceholders(node *yaml.Node, pth string, inList bool, fn func(node *yaml.Node, pth string, inList bool) bool) bool {
+	switch node.Kind {
+	case yaml.MappingNode:
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := strings.ReplaceAll(node.Content[i].Value, ".", `\.`)
+			if pth != "" {
+				key = pth + "." + key
+			}
+
+			if !walkPlaceholders(node.Content[i+1], key, inList, fn) {
+				content = append(content, node.Content[i], node.Content[i+1])
+			}
+		}
+		node.Content = content
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			walkPlaceholders(child, fmt.Sprintf("%s[%d]", pth, i), true, fn)
+		}
+	case yaml.ScalarNode:
+		if envRefRegex.MatchString(node.Value) {
+			return fn(node, pth, inList) && !inList
+		}
+	}
+
+	return false
+}
+
+func writeExport(name string, docs ...any) {
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			logger.Fatalf("failed to marshal %s: %s", name, err)
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		logger.Fatalf("failed to marshal %s: %s", name, err)
+	}
+
+	file := path.Join(Args.ExportCmd.Out, name)
+	if !Args.DryRun {
+		if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+			logger.Fatalf("failed to write %s: %s", file, err)
+		}
+	}
+
+	logger.Infof("Exported %s", file)
+}
+
+func exportPrepare() {
+	if Args.DryRun {
+		logger.Info("Dry run, not writing files")
+	} else if err := os.MkdirAll(Args.ExportCmd.Out, 0755); err != nil {
+		logger.Fatalf("failed to create output directory: %s", err)
+	}
+
+	if len(Manifest.Singles) != 0 {
+		logger.Warnf("%d singles are not exported, commit their files next to the exported objects instead", len(Manifest.Singles))
+	}
+}
+
+// exportableRepo returns the repo of a release, or false if the release cannot be exported.
+func exportableRepo(release types.ManifestRelease) (types.ManifestRepo, bool) {
+	if release.Chart.Repo == "" {
+		logger.Warnf("release %s uses a local chart which cannot be exported, skipping", release.Name)
+		return types.ManifestRepo{}, false
+	}
+
+	repo := Manifest.RepoByName(release.Chart.Repo)
+	if repo.Name == "" {
+		logger.Warnf("release %s uses repo %s which is not in the manifest, skipping", release.Name, release.Chart.Repo)
+		return repo, false
+	}
+
+	return repo, true
+}
+
+var exportArgoCmd = &cobra.Command{
+	Use:         "argocd",
+	Short:       "Export releases as Argo CD Applications",
+	Long:        "Export releases as Argo CD Applications, one file per release",
+	Example:     "   helm-manager export argocd --out gitops/apps\n   helm-manager export argocd --out gitops/apps --secrets secret --secret-name secret/data/helm-manager",
+	Annotations: map[string]string{AnnotationOffline: "true"},
+	Args:        exportArgs("Helm Manager Export Argo CD"),
+	Run: func(cmd *cobra.Command, _ []string) {
+		namespace := utils.OrStr(Args.Namespace, "argocd")
+		exportPrepare()
+
+		for _, release := range Manifest.Releases {
+			repo, ok := exportableRepo(release)
+			if !ok {
+				continue
+			}
+
+			values, err := exportValues(release)
+			if err != nil {
+				logger.Fatalf("failed to read values of %s: %s", release.Name, err)
+			}
+
+			if Args.ExportCmd.Secrets == SecretsSecret {
+				// argocd-vault-plugin placeholders can be used anywhere in a string
+				walkPlaceholders(values, "", false, func(node *yaml.Node, _ string, _ bool) bool {
+					node.Value = envRefRegex.ReplaceAllString(node.Value, fmt.Sprintf("<path:%s#$1>", Args.ExportCmd.SecretName))
+					return false
+				})
+			}
+
+			app := types.ArgoApplication{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+				Metadata: types.KubeMetadata{
+					Name:      release.Name,
+					Namespace: namespace,
+				},
+				Spec: types.ArgoApplicationSpec{
+					Project: Args.ExportCmd.Project,
+					Source: types.ArgoApplicationSource{
+						RepoURL:        repo.URL,
+						Chart:          release.Chart.Name,
+						TargetRevision: release.Chart.Version,
+						Helm: &types.ArgoApplicationHelm{
+							ReleaseName: release.Name,
+						},
+					},
+					Destination: types.ArgoApplicationDestination{
+						Server:    Args.ExportCmd.Server,
+						Namespace: release.Namespace,
+					},
+				},
+			}
+
+			if len(values.Content) != 0 {
+				app.Spec.Source.Helm.ValuesObject = values
+			}
+
+			if len(release.DependsOn) != 0 {
+				logger.Warnf("release %s depends on %s, Argo CD has no equivalent, use sync waves to order applications", release.Name, strings.Join(release.DependsOn, ", "))
+			}
+
+			writeExport(release.Name+".yaml", app)
+		}
+
+		logger.Info("export complete")
+	},
+}
+
+var exportFluxCmd = &cobra.Command{
+	Use:         "flux",
+	Short:       "Export repos and releases as Flux HelmRepositories and HelmReleases",
+	Long:        "Export repos and releases as Flux HelmRepositories and HelmReleases, repos are written to repositories.yaml and releases to one file each",
+	Example:     "   helm-manager export flux --out gitops/releases\n   helm-manager export flux --out gitops/releases --secrets secret",
+	Annotations: map[string]string{AnnotationOffline: "true"},
+	Args:        exportArgs("Helm Manager Export Flux"),
+	Run: func(cmd *cobra.Command, _ []string) {
+		namespace := utils.OrStr(Args.Namespace, "flux-system")
+		exportPrepare()
+
+		repos := make([]any, 0, len(Manifest.Repos))
+		for _, repo := range Manifest.Repos {
+			repos = append(repos, types.FluxHelmRepository{
+				APIVersion: "source.toolkit.fluxcd.io/v1beta2",
+				Kind:       "HelmRepository",
+				Metadata: types.KubeMetadata{
+					Name:      repo.Name,
+					Namespace: namespace,
+				},
+				Spec: types.FluxHelmRepositorySpec{
+					Interval: Args.ExportCmd.Interval,
+					URL:      repo.URL,
+				},
+			})
+		}
+
+		if len(repos) != 0 {
+			writeExport("repositories.yaml", repos...)
+		}
+
+		for _, release := range Manifest.Releases {
+			repo, ok := exportableRepo(release)
+			if !ok {
+				continue
+			}
+
+			values, err := exportValues(release)
+			if err != nil {
+				logger.Fatalf("failed to read values of %s: %s", release.Name, err)
+			}
+
+			helmRelease := types.FluxHelmRelease{
+				APIVersion: "helm.toolkit.fluxcd.io/v2beta1",
+				Kind:       "HelmRelease",
+				Metadata: types.KubeMetadata{
+					Name:      release.Name,
+					Namespace: namespace,
+				},
+				Spec: types.FluxHelmReleaseSpec{
+					Interval:        Args.ExportCmd.Interval,
+					ReleaseName:     release.Name,
+					TargetNamespace: release.Namespace,
+					Chart: types.FluxHelmChartTemplate{
+						Spec: types.FluxHelmChartSpec{
+							Chart:   release.Chart.Name,
+							Version: release.Chart.Version,
+							SourceRef: types.FluxSourceRef{
+								Kind:      "HelmRepository",
+								Name:      repo.Name,
+								Namespace: namespace,
+							},
+						},
+					},
+				},
+			}
+
+			if Args.ExportCmd.Secrets == SecretsSecret {
+				// inline values are merged over valuesFrom, so placeholders taken from the secret must be removed
+				walkPlaceholders(values, "", false, func(node *yaml.Node, pth string, inList bool) bool {
+					match := exactEnvRefRegex.FindStringSubmatch(node.Value)
+					if match == nil {
+						logger.Warnf("release %s: %s only partially consists of a placeholder and is kept as is", release.Name, pth)
+						return false
+					}
+
+					if inList {
+						logger.Warnf("release %s: %s is inside a list which would override the secret, the placeholder is kept as is", release.Name, pth)
+						return false
+					}
+
+					helmRelease.Spec.ValuesFrom = append(helmRelease.Spec.ValuesFrom, types.FluxValuesReference{
+						Kind:       "Secret",
+						Name:       Args.ExportCmd.SecretName,
+						ValuesKey:  match[1],
+						TargetPath: pth,
+					})
+
+					return true
+				})
+			}
+
+			if len(values.Content) != 0 {
+				helmRelease.Spec.Values = values
+			}
+
+			for _, dep := range release.DependsOn {
+				kind, name := types.ParseDependency(dep)
+				if _, idx := Manifest.ReleaseIdxByName(name); idx == -1 || kind == types.EntryKindSingle {
+					logger.Warnf("release %s depends on single %s, Flux HelmReleases can only depend on other HelmReleases", release.Name, name)
+					continue
+				}
+
+				helmRelease.Spec.DependsOn = append(helmRelease.Spec.DependsOn, types.FluxDependency{
+					Name:      name,
+					Namespace: namespace,
+				})
+			}
+
+			writeExport(release.Name+".yaml", helmRelease)
+		}
+
+		logger.Info("export complete")
+	},
+}
@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	importCmd.AddCommand(importGitopsCmd)
+	importGitopsCmd.Flags().StringVarP(&Args.File, "file", "f", "", "Path to the Argo CD or Flux yaml")
+	importGitopsCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Overwrite existing release files")
+}
+
+var repoNameRegex = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// repoNameFromURL derives a repo name for urls which are not named in the source, such as Argo CD repoURLs.
+func repoNameFromURL(repoURL string) string {
+	name := ""
+	if u, err := url.Parse(repoURL); err == nil {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		name = segments[len(segments)-1]
+		if name == "" || name == "charts" {
+			name = strings.Split(u.Hostname(), ".")[0]
+		}
+	}
+
+	name = strings.Trim(repoNameRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		name = "repo"
+	}
+
+	unique := name
+	for i := 2; ; i++ {
+		existing := Manifest.RepoByName(unique)
+		if existing.Name == "" || strings.TrimSuffix(existing.URL, "/") == strings.TrimSuffix(repoURL, "/") {
+			return unique
+		}
+
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+func gitopsValues(node *yaml.Node) ([]byte, error) {
+	if node == nil || len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	return utils.MarshalYaml(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}})
+}
+
+// argoRelease is an Argo CD Application which can be imported, the release is added once the charts of its repo are loaded.
+type argoRelease struct {
+	name      string
+	namespace string
+	repo      string
+	chart     string
+	version   string
+	values    []byte
+}
+
+// importArgoApplication checks an Application and adds its repo to the manifest, only applications which can be imported add a repo.
+func importArgoApplication(r *releaseImporter, app types.ArgoApplication) (argoRelease, bool) {
+	name := app.Metadata.Name
+	if len(app.Spec.Sources) != 0 {
+		r.report("application %s: multiple sources are not supported, skipping", name)
+		return argoRelease{}, false
+	}
+
+	source := app.Spec.Source
+	if source.Chart == "" || source.Path != "" {
+		r.report("application %s: only helm repository sources are supported, skipping", name)
+		return argoRelease{}, false
+	}
+
+	if !strings.HasPrefix(source.RepoURL, "http://") && !strings.HasPrefix(source.RepoURL, "https://") {
+		r.report("application %s: oci repository %s is not supported, skipping", name, source.RepoURL)
+		return argoRelease{}, false
+	}
+
+	var values []byte
+	if helm := source.Helm; helm != nil {
+		if helm.ReleaseName != "" {
+			name = helm.ReleaseName
+		}
+
+		if len(helm.ValueFiles) != 0 {
+			r.report("application %s: valueFiles %s are not supported", name, strings.Join(helm.ValueFiles, ", "))
+		}
+
+		if len(helm.Parameters) != 0 {
+			r.report("application %s: helm parameters are not supported", name)
+		}
+
+		var err error
+		if helm.ValuesObject != nil {
+			if helm.Values != "" {
+				r.report("application %s: both values and valuesObject are set, only valuesObject is imported", name)
+			}
+
+			values, err = gitopsValues(helm.ValuesObject)
+		} else if helm.Values != "" {
+			values = []byte(helm.Values)
+			_, err = utils.ParseYaml(values)
+		}
+
+		if err != nil {
+			r.report("application %s: invalid values, %v, skipping", name, err)
+			return argoRelease{}, false
+		}
+	}
+
+	if r.skipRelease(name) {
+		return argoRelease{}, false
+	}
+
+	return argoRelease{
+		name:      name,
+		namespace: app.Spec.Destination.Namespace,
+		repo:      r.addRepo(repoNameFromURL(source.RepoURL), source.RepoURL),
+		chart:     source.Chart,
+		version:   source.TargetRevision,
+		values:    values,
+	}, true
+}
+
+// importFluxHelmRelease imports a HelmRelease, repos maps "namespace/name" of the HelmRepositories to repo names in the manifest.
+func importFluxHelmRelease(r *releaseImporter, repos map[string]string, release types.FluxHelmRelease) string {
+	spec := release.Spec
+	name := spec.ReleaseName
+	if name == "" {
+		// the default release name flux uses
+		name = release.Metadata.Name
+		if spec.TargetNamespace != "" {
+			name = fmt.Sprintf("%s-%s", spec.TargetNamespace, name)
+		}
+	}
+
+	sourceRef := spec.Chart.Spec.SourceRef
+	if sourceRef.Kind != "HelmRepository" {
+		r.report("helmrelease %s: %s sources are not supported, skipping", name, sourceRef.Kind)
+		return ""
+	}
+
+	repo, ok := repos[fmt.Sprintf("%s/%s", utils.OrStr(sourceRef.Namespace, release.Metadata.Namespace), sourceRef.Name)]
+	if !ok {
+		if Manifest.RepoByName(sourceRef.Name).Name == "" {
+			r.report("helmrelease %s: HelmRepository %s is not in the file or the manifest, skipping", name, sourceRef.Name)
+			return ""
+		}
+
+		repo = strings.ToLower(sourceRef.Name)
+	}
+
+	for _, from := range spec.ValuesFrom {
+		r.report("helmrelease %s: values from %s %s are not imported", name, from.Kind, from.Name)
+	}
+
+	values, err := gitopsValues(spec.Values)
+	if err != nil {
+		r.report("helmrelease %s: invalid values, %v, skipping", name, err)
+		return ""
+	}
+
+	r.addRelease(name, utils.OrStr(spec.TargetNamespace, release.Metadata.Namespace), repo, spec.Chart.Spec.Chart, spec.Chart.Spec.Version, values)
+
+	return name
+}
+
+var importGitopsCmd = &cobra.Command{
+	Use:     "gitops",
+	Short:   "Import releases from Argo CD Applications or Flux HelmReleases",
+	Long:    "Import repos and releases from a file containing Argo CD Applications or Flux HelmRepositories and HelmReleases",
+	Example: "   helm-manager import gitops [FILE]\n   helm-manager import gitops apps/redis.yaml --dry-run",
+	Args: ui.PositionalArgs([]ui.RequiredArg{
+		ui.Arg[string]{
+			Name:       "file",
+			Ptr:        &Args.File,
+			Positional: true,
+			Validator:  types.PathValidator("file", false, false, true, false),
+			UI:         ui.PromptUiFunc[string]("File"),
+		},
+	}, func(cmd *cobra.Command) {
+		zap.S().Infof("* %s *", color.MagentaString("Helm Manager Import GitOps"))
+
+		ManifestExist(cmd)
+	}),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(Args.File)
+		if err != nil {
+			logger.Fatalf("failed to read %s: %s", Args.File, err)
+		}
+
+		docs := []*yaml.Node{}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			node := &yaml.Node{}
+			if err := dec.Decode(node); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				logger.Fatalf("failed to parse %s: %s", Args.File, err)
+			}
+
+			if !utils.NodeIsZero(node) {
+				docs = append(docs, node)
+			}
+		}
+
+		r := newReleaseImporter()
+		apps := []types.ArgoApplication{}
+		helmRepos := []types.FluxHelmRepository{}
+		helmReleases := []types.FluxHelmRelease{}
+
+		for _, doc := range docs {
+			var err error
+
+			switch kind := utils.MappingValue(doc, "kind"); {
+			case kind == nil:
+				r.report("document on line %d has no kind, skipping", doc.Line)
+			case kind.Value == "Application":
+				app := types.ArgoApplication{}
+				err = doc.Decode(&app)
+				apps = append(apps, app)
+			case kind.Value == "HelmRepository":
+				repo := types.FluxHelmRepository{}
+				err = doc.Decode(&repo)
+				helmRepos = append(helmRepos, repo)
+			case kind.Value == "HelmRelease":
+				release := types.FluxHelmRelease{}
+				err = doc.Decode(&release)
+				helmReleases = append(helmReleases, release)
+			default:
+				r.report("%s on line %d is not supported, skipping", kind.Value, kind.Line)
+			}
+
+			if err != nil {
+				logger.Fatalf("failed to decode document on line %d: %s", doc.Line, err)
+			}
+		}
+
+		repos := map[string]string{}
+		for _, repo := range helmRepos {
+			if repo.Spec.Type == "oci" {
+				r.report("HelmRepository %s: oci repositories are not supported", repo.Metadata.Name)
+				continue
+			}
+
+			name := r.addRepo(repo.Metadata.Name, repo.Spec.URL)
+			repos[fmt.Sprintf("%s/%s", repo.Metadata.Namespace, repo.Metadata.Name)] = name
+		}
+
+		argoReleases := []argoRelease{}
+		for _, app := range apps {
+			if release, ok := importArgoApplication(r, app); ok {
+				argoReleases = append(argoReleases, release)
+			}
+		}
+
+		r.loadCharts()
+
+		for _, release := range argoReleases {
+			r.addRelease(release.name, release.namespace, release.repo, release.chart, release.version, release.values)
+		}
+
+		// flux dependencies refer to HelmRelease objects, not helm release names
+		releaseNames := map[string]string{}
+		for _, release := range helmReleases {
+			releaseNames[release.Metadata.Name] = importFluxHelmRelease(r, repos, release)
+		}
+
+		for _, release := range helmReleases {
+			deps := []string{}
+			for _, dep := range release.Spec.DependsOn {
+				deps = append(deps, utils.OrStr(releaseNames[dep.Name], dep.Name))
+			}
+
+			r.dependsOn(releaseNames[release.Metadata.Name], deps)
+		}
+
+		r.write(len(apps) + len(helmReleases))
+	},
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	importCmd.AddCommand(importHelmfileCmd)
+	importHelmfileCmd.Flags().StringVarP(&Args.File, "file", "f", "", "Path to the helmfile.yaml")
+	importHelmfileCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Overwrite existing release files")
+}
+
+func reportExtra(r *releaseImporter, what string, extra map[string]yaml.Node) {
+	for _, key := range types.ExtraKeys(extra) {
+		r.report("%s: %s is not supported", what, key)
+	}
+}
+
+// repos maps the repository names of the helmfile to the names used in the manifest.
+func importHelmfileRelease(r *releaseImporter, dir string, repos map[string]string, release types.HelmfileRelease) {
+	reportExtra(r, fmt.Sprintf("release %s", release.Name), release.Extra)
+
+	if release.Installed != nil && !*release.Installed {
+		r.report("release %s: is marked as not installed, skipping", release.Name)
+		return
+	}
+
+	if strings.HasPrefix(release.Chart, "oci://") || strings.HasPrefix(release.Chart, ".") || filepath.IsAbs(release.Chart) {
+		r.report("release %s: chart %s is not from a repository, add it as a local chart or repo, skipping", release.Name, release.Chart)
+		return
+	}
+
+	repo, chartName, ok := strings.Cut(release.Chart, "/")
+	if alias, found := repos[strings.ToLower(repo)]; found {
+		repo = alias
+	}
+
+	if !ok || Manifest.RepoByName(repo).Name == "" {
+		r.report("release %s: chart %s is not from a repository in the manifest, skipping", release.Name, release.Chart)
+		return
+	}
+
+	values := utils.HelmfileReleaseValues(dir, release, r.report)
+	data, err := utils.MarshalYaml(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{values}})
+	if err != nil {
+		r.report("release %s: failed to marshal values, %v", release.Name, err)
+		return
+	}
+
+	r.addRelease(release.Name, release.Namespace, strings.ToLower(repo), chartName, release.Version, data)
+	r.labels(release.Name, release.Labels)
+}
+
+var importHelmfileCmd = &cobra.Command{
+	Use:     "helmfile",
+	Short:   "Import repositories and releases from a helmfile",
+	Long:    "Import repositories and releases from a helmfile, anything which cannot be translated is reported",
+	Example: "   helm-manager import helmfile [PATH]\n   helm-manager import helmfile ../infra/helmfile.yaml --dry-run",
+	Args: ui.PositionalArgs([]ui.RequiredArg{
+		ui.Arg[string]{
+			Name:       "path",
+			Ptr:        &Args.File,
+			Positional: true,
+			Validator:  types.PathValidator("path", false, false, true, false),
+			UI:         ui.PromptUiFunc[string]("Path to helmfile.yaml"),
+		},
+	}, func(cmd *cobra.Command) {
+		zap.S().Infof("* %s *", color.MagentaString("Helm Manager Import Helmfile"))
+
+		ManifestExist(cmd)
+	}),
+	Run: func(cmd *cobra.Command, args []string) {
+		helmfile, docs, err := utils.ReadHelmfile(Args.File)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		r := newReleaseImporter()
+		dir := filepath.Dir(Args.File)
+		repos := map[string]string{}
+
+		if docs > 1 {
+			r.report("helmfile: only the first of %d documents is imported", docs)
+		}
+
+		reportExtra(r, "helmfile", helmfile.Extra)
+
+		envs := make([]string, 0, len(helmfile.Environments))
+		for name := range helmfile.Environments {
+			envs = append(envs, name)
+		}
+		sort.Strings(envs)
+
+		for _, name := range envs {
+			env := helmfile.Environments[name]
+			if len(env.Values) != 0 {
+				r.report("environment %s: values are only used by helmfile templates, they are not imported", name)
+			}
+
+			reportExtra(r, fmt.Sprintf("environment %s", name), env.Extra)
+		}
+
+		for _, repo := range helmfile.Repositories {
+			reportExtra(r, fmt.Sprintf("repository %s", repo.Name), repo.Extra)
+
+			if repo.OCI {
+				r.report("repository %s: oci repositories are not supported", repo.Name)
+				continue
+			}
+
+			repos[strings.ToLower(repo.Name)] = r.addRepo(repo.Name, repo.URL)
+		}
+
+		r.loadCharts()
+
+		for _, release := range helmfile.Releases {
+			importHelmfileRelease(r, dir, repos, release)
+		}
+
+		// needs are "[kubecontext/][namespace/]name"
+		for _, release := range helmfile.Releases {
+			deps := make([]string, 0, len(release.Needs))
+			for _, need := range release.Needs {
+				parts := strings.Split(need, "/")
+				deps = append(deps, parts[len(parts)-1])
+			}
+
+			r.dependsOn(release.Name, deps)
+		}
+
+		r.write(len(helmfile.Releases))
+	},
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/redact"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&Args.Name, "name", "", "Only show entries for this release or single")
+	historyCmd.Flags().IntVar(&Args.HistoryCmd.Limit, "limit", 0, "Only show the last N entries")
+	historyCmd.Flags().StringVarP(&Args.Output, "output", "o", "text", "Output format, text or json")
+}
+
+func auditResultColor(result types.AuditResult) string {
+	switch result {
+	case types.AuditResultSuccess:
+		return color.GreenString(string(result))
+	case types.AuditResultFailure:
+		return color.RedString(string(result))
+	default:
+		return color.YellowString(string(result))
+	}
+}
+
+var historyCmd = &cobra.Command{
+	Use:         "history",
+	Short:       "Show the audit log of changes made with helm-manager",
+	Long:        "Show the audit log of changes made with helm-manager, optionally filtered to a single release or single",
+	Example:     "   helm-manager history [NAME]\n   helm-manager history mongo --limit 10",
+	Annotations: map[string]string{AnnotationOffline: "true"},
+	Args: ui.PositionalArgs([]ui.RequiredArg{
+		ui.Arg[string]{
+			Name:       "name",
+			Ptr:        &Args.Name,
+			Positional: true,
+			Callback: func(s string) error {
+				Args.Name = strings.ToLower(s)
+				return nil
+			},
+			Completions: types.FutureFromFunc(func() []string {
+				names := []string{}
+				for _, release := range Manifest.Releases {
+					names = append(names, release.Name)
+				}
+				for _, single := range Manifest.Singles {
+					names = append(names, single.Name)
+				}
+
+				return names
+			}),
+		},
+	}, nil),
+	Run: func(cmd *cobra.Command, _ []string) {
+		if Args.Output != "text" && Args.Output != "json" {
+			logger.Fatalf("unknown output format \"%s\", must be text or json", Args.Output)
+		}
+
+		entries, err := utils.ReadAudit(AuditPath())
+		if err != nil {
+			logger.Fatalf("failed to read audit log: %s", err)
+		}
+
+		filtered := []types.AuditEntry{}
+		for _, entry := range entries {
+			if Args.Name == "" || strings.ToLower(entry.Name) == Args.Name {
+				filtered = append(filtered, entry)
+			}
+		}
+
+		if Args.HistoryCmd.Limit > 0 && len(filtered) > Args.HistoryCmd.Limit {
+			filtered = filtered[len(filtered)-Args.HistoryCmd.Limit:]
+		}
+
+		if Args.Output == "json" {
+			enc := json.NewEncoder(redact.Writer(os.Stdout))
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(filtered); err != nil {
+				logger.Fatalf("failed to encode history: %s", err)
+			}
+
+			return
+		}
+
+		zap.S().Infof("* %s *", color.BlueString("Helm Manager History"))
+
+		if len(filtered) == 0 {
+			logger.Info("No history found")
+			return
+		}
+
+		for _, entry := range filtered {
+			target := entry.Name
+			if entry.Namespace != "" {
+				target = fmt.Sprintf("%s/%s", entry.Namespace, entry.Name)
+			}
+
+			version := entry.NewVersion
+			if entry.OldVersion != "" && entry.OldVersion != entry.NewVersion {
+				version = fmt.Sprintf("%s -> %s", color.RedString(entry.OldVersion), color.GreenString(utils.OrStr(entry.NewVersion, "-")))
+			}
+
+			commit := entry.GitCommit
+			if len(commit) > 8 {
+				commit = commit[:8]
+			}
+			if entry.GitDirty {
+				commit += "-dirty"
+			}
+
+			zap.S().Infof("%s %s %s %s %s %s %s %s %s",
+				color.New(color.Faint).Sprint(entry.Time.Local().Format("2006-01-02 15:04:05")),
+				color.MagentaString(entry.User),
+				color.YellowString(string(entry.Action)),
+				entry.Kind,
+				color.CyanString(target),
+				version,
+				auditResultColor(entry.Result),
+				color.New(color.Faint).Sprint(utils.OrStr(commit, "-")),
+				color.New(color.Faint).Sprint(entry.KubeContext),
+			)
+
+			if entry.Error != "" {
+				zap.S().Infof("    %s", color.RedString(entry.Error))
+			}
+		}
+	},
+}
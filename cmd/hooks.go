@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+)
+
+type HookContext struct {
+	Kind         string // release or single
+	Name         string
+	Namespace    string
+	ChartVersion string
+	Values       []byte // rendered values, exposed to the hooks through a temporary file
+}
+
+func (h HookContext) env(event types.HookEvent, valuesPath string) []string {
+	return []string{
+		"HELM_MANAGER_HOOK=" + string(event),
+		"HELM_MANAGER_KIND=" + h.Kind,
+		"HELM_MANAGER_RELEASE=" + h.Name,
+		"HELM_MANAGER_NAMESPACE=" + h.Namespace,
+		"HELM_MANAGER_CHART_VERSION=" + h.ChartVersion,
+		"HELM_MANAGER_VALUES_PATH=" + valuesPath,
+		"HELM_MANAGER_CONTEXT=" + Args.Context,
+		"HELM_MANAGER_DRY_RUN=" + strconv.FormatBool(Args.DryRun),
+	}
+}
+
+func runHook(event types.HookEvent, hook types.ManifestHook, hctx HookContext, valuesPath string) error {
+	timeout, err := hook.TimeoutDuration()
+	if err != nil {
+		return fmt.Errorf("invalid timeout for %s hook \"%s\", %v", event, hook, err)
+	}
+
+	ctx, cancel := context.WithTimeout(RootContext, timeout)
+	defer cancel()
+
+	done := utils.Loader(utils.LoaderOptions{
+		FetchingText: fmt.Sprintf("Running %s hook %s for %s %s", event, hook, hctx.Kind, hctx.Name),
+		SuccessText:  fmt.Sprintf("Ran %s hook %s for %s %s", event, hook, hctx.Kind, hctx.Name),
+		FailureText:  fmt.Sprintf("Failed %s hook %s for %s %s", event, hook, hctx.Kind, hctx.Name),
+	})
+
+	resp, err := utils.ExecuteShell(ctx, Args.Context, hctx.env(event, valuesPath), hook.Command)
+	done(err == nil)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s hook \"%s\" timed out after %s\n%s", event, hook, timeout, resp)
+	} else if err != nil {
+		return fmt.Errorf("%s hook \"%s\" failed: %v\n%s", event, hook, err, resp)
+	}
+
+	logger.Debugf("%s hook \"%s\" output:\n%s", event, hook, resp)
+
+	return nil
+}
+
+// RunHooks runs every hook registered for the event in order, stopping at the first failure.
+func RunHooks(hooks types.ManifestHooks, event types.HookEvent, hctx HookContext) error {
+	list := hooks.ByEvent(event)
+	if len(list) == 0 {
+		return nil
+	}
+
+	if Args.DryRun {
+		for _, hook := range list {
+			logger.Infof("Dry run, not running %s hook \"%s\" for %s %s", event, hook, hctx.Kind, hctx.Name)
+		}
+
+		return nil
+	}
+
+	valuesPath := ""
+	if hctx.Values != nil {
+		file, err := os.CreateTemp("", fmt.Sprintf("helm-manager-%s-*.yaml", hctx.Name))
+		if err != nil {
+			return fmt.Errorf("failed to create values file for hooks, %v", err)
+		}
+		defer os.Remove(file.Name())
+
+		_, err = file.Write(hctx.Values)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write values file for hooks, %v", err)
+		}
+
+		valuesPath = file.Name()
+	}
+
+	for _, hook := range list {
+		if err := runHook(event, hook, hctx, valuesPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WithHooks wraps an action with its pre and post hooks, when the action or any of the hooks fail the on_failure hooks are run.
+// A failing hook is treated exactly like a failing action, the error is returned to the caller.
+func WithHooks(hooks types.ManifestHooks, pre types.HookEvent, post types.HookEvent, hctx HookContext, action func() error) error {
+	err := RunHooks(hooks, pre, hctx)
+	if err == nil {
+		err = action()
+	}
+	if err == nil {
+		err = RunHooks(hooks, post, hctx)
+	}
+
+	if err != nil {
+		if ferr := RunHooks(hooks, types.HookOnFailure, hctx); ferr != nil {
+			logger.Error(ferr)
+		}
+	}
+
+	return err
+}
@@ -20,12 +20,23 @@ func init() {
 
 	{
 		importCmd.AddCommand(importReleaseCmd)
-		importReleaseCmd.Flags().StringVarP(&Args.Name, "name", "", "", "Name of the release to import")
+		importReleaseCmd.Flags().StringSliceVarP(&Args.Names, "name", "", nil, "Name of the release to import, as name or namespace/name, can be repeated")
+		importReleaseCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Import the releases matching the selector, like namespace=db,chart!=redis")
 		importReleaseCmd.Flags().StringVarP(&Args.Namespace, "namespace", "n", "", "Namespace of the release to import")
 		importReleaseCmd.Flags().BoolVarP(&Args.ImportCmd.All, "all", "", false, "Import all releases")
 		importReleaseCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Overwrite existing release files")
 	}
 
+	{
+		importCmd.AddCommand(importSingleCmd)
+		importSingleCmd.Flags().StringVarP(&Args.Name, "name", "", "", "Name of the single to create")
+		importSingleCmd.Flags().StringVarP(&Args.ImportCmd.Kind, "kind", "", "", "Kind of the resources to import, comma separated for multiple kinds")
+		importSingleCmd.Flags().StringVarP(&Args.ImportCmd.Resource, "resource", "", "", "Name of the resource to import")
+		importSingleCmd.Flags().StringVarP(&Args.Namespace, "namespace", "n", "", "Namespace of the resources to import")
+		importSingleCmd.Flags().StringVarP(&Args.ImportCmd.Selector, "selector", "l", "", "Label selector of the resources to import")
+		importSingleCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Overwrite existing single files")
+	}
+
 	{
 		importCmd.AddCommand(importRepoCmd)
 		importRepoCmd.Flags().StringVarP(&Args.Name, "name", "", "", "Name of the release to import")
@@ -35,8 +46,8 @@ func init() {
 
 var importCmd = &cobra.Command{
 	Use:   "import",
-	Short: "Import releases, repositories from other sources",
-	Long:  "Import releases, repositories from other sources",
+	Short: "Import releases, singles, repositories from other sources",
+	Long:  "Import releases, singles, repositories from other sources",
 	Args:  ui.SubCommandRequired(cobra.NoArgs),
 	Run: func(cmd *cobra.Command, args []string) {
 		zap.S().Infof("* %s *\r", color.MagentaString("Helm Manager Import"))
@@ -54,6 +65,95 @@ var importCmd = &cobra.Command{
 	},
 }
 
+// importNameParts splits a release given as namespace/name, the namespace is empty for a plain name.
+func importNameParts(name string) (string, string) {
+	if idx := strings.Index(name, "/"); idx != -1 {
+		return name[:idx], name[idx+1:]
+	}
+
+	return "", name
+}
+
+// importTarget is the name of the release the namespace is picked for, the namespace argument is only used with a single release.
+func importTarget() string {
+	if len(Args.Names) == 0 {
+		return ""
+	}
+
+	_, name := importNameParts(Args.Names[0])
+	return name
+}
+
+// unimportedReleases are the releases in the cluster which are not in the manifest yet, limited to --namespace when it is set.
+func unimportedReleases() ([]HelmReleaseChart, error) {
+	releases, err := HelmReleaseChartFuture.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []HelmReleaseChart{}
+	for _, v := range releases {
+		if Args.Namespace != "" && !strings.EqualFold(v.Namespace, Args.Namespace) {
+			continue
+		}
+
+		if _, idx := Manifest.ReleaseIdxByName(v.Name); idx != -1 {
+			continue
+		}
+
+		ret = append(ret, v)
+	}
+
+	return ret, nil
+}
+
+// selectedImportReleases finds the cluster releases picked by name or matched by --selector.
+func selectedImportReleases(releases []HelmReleaseChart) []HelmReleaseChart {
+	if Args.Selector != "" {
+		selector := parseSelectorArg()
+
+		unimported, err := unimportedReleases()
+		if err != nil {
+			logger.Fatalf("Failed to get releases: %s", err)
+		}
+
+		selected := types.SelectMatching(selector, unimported)
+		if len(selected) == 0 {
+			logger.Fatalf("No unimported releases match the selector %s", selector)
+		}
+
+		return selected
+	}
+
+	selected := make([]HelmReleaseChart, 0, len(Args.Names))
+	for _, name := range Args.Names {
+		namespace, name := importNameParts(name)
+		if namespace == "" {
+			namespace = Args.Namespace
+		}
+
+		found := []HelmReleaseChart{}
+		for _, v := range releases {
+			if v.Name == name && (namespace == "" || strings.EqualFold(v.Namespace, namespace)) {
+				found = append(found, v)
+			}
+		}
+
+		switch {
+		case len(found) == 0 && namespace == "":
+			logger.Fatalf("Could not find release \"%s\"", name)
+		case len(found) == 0:
+			logger.Fatalf("Could not find release \"%s\" in namespace \"%s\"", name, namespace)
+		case len(found) > 1:
+			logger.Fatalf("The release \"%s\" is in several namespaces, use namespace/name to pick one", name)
+		}
+
+		selected = append(selected, found[0])
+	}
+
+	return selected
+}
+
 var importReleaseCmd = &cobra.Command{
 	Use:   "release",
 	Short: "Import releases from a cluster",
@@ -63,14 +163,16 @@ var importReleaseCmd = &cobra.Command{
 			Name: "all",
 			Ptr:  &Args.ImportCmd.All,
 			Disabled: types.FutureFromFunc(func() bool {
-				return Args.Name == "" && Args.Namespace == ""
+				return (len(Args.Names) == 0 && Args.Namespace == "") || Args.Selector != ""
 			}),
 			UI: ui.PromptUiConfirmFunc("Import all releases from the cluster", false),
 		},
-		ui.Arg[string]{
-			Name:     "name",
-			Ptr:      &Args.Name,
-			Disabled: types.FutureFromPtr(&Args.ImportCmd.All),
+		ui.ListArg{
+			Name: "name",
+			Ptr:  &Args.Names,
+			Disabled: types.FutureFromFunc(func() bool {
+				return Args.ImportCmd.All || Args.Selector != ""
+			}),
 			Validator: types.MultiValidator(
 				types.EqualValidator(
 					types.StringerFunc(func() string {
@@ -111,69 +213,47 @@ var importReleaseCmd = &cobra.Command{
 				),
 			),
 			Positional: true,
-			UI: ui.PromptUiSelectorFunc[string]("Release", "", func(i int) error {
-				releases, err := HelmReleaseChartFuture.Get()
+			UI: ui.PromptUiMultiSelectorFunc("Releases", "Which releases do you want to import", func(idxs []int) error {
+				releases, err := unimportedReleases()
 				if err != nil {
 					return err
 				}
 
-				idx := 0
-				for _, v := range releases {
-					if Args.Namespace != "" && strings.ToLower(v.Namespace) != strings.ToLower(Args.Namespace) {
-						continue
-					}
-
-					if _, idx := Manifest.ReleaseIdxByName(v.Name); idx != -1 {
-						continue
-					}
-
-					if idx == i {
-						Args.Name = v.Name
-						if Args.Namespace == "" {
-							Args.Namespace = v.Namespace
-						}
-						idx = -1
-						break
-					}
-					idx++
+				if len(idxs) == 0 {
+					return fmt.Errorf("nothing selected")
 				}
 
-				if idx != -1 {
-					return fmt.Errorf("Could not find release")
+				Args.Names = make([]string, len(idxs))
+				for i, idx := range idxs {
+					Args.Names[i] = releases[idx].Selected()
 				}
 
 				return nil
 			}, types.FutureFromFuncErr(func() ([]types.Selectable, error) {
-				releases, err := HelmReleaseChartFuture.Get()
+				releases, err := unimportedReleases()
 				if err != nil {
 					return nil, err
 				}
 
-				ret := []types.Selectable{}
-				for _, v := range releases {
-					if Args.Namespace != "" && strings.ToLower(v.Namespace) != strings.ToLower(Args.Namespace) {
-						continue
-					}
-
-					if _, idx := Manifest.ReleaseIdxByName(v.Name); idx != -1 {
-						continue
-					}
-
-					ret = append(ret, v)
+				if len(releases) == 0 {
+					return nil, fmt.Errorf("No unimported releases found")
 				}
 
-				if len(ret) == 0 {
-					return nil, fmt.Errorf("No unimported releases found")
+				ret := make([]types.Selectable, len(releases))
+				for i, v := range releases {
+					ret[i] = v
 				}
 
 				return ret, nil
 			})),
-			Callback: func(name string) error {
-				if Args.Namespace == "" {
-					name := strings.Split(name, "/")
-					if len(name) == 2 {
-						Args.Namespace = name[0]
-						Args.Name = name[1]
+			Callback: func(names []string) error {
+				for i, name := range names {
+					names[i] = strings.ToLower(name)
+				}
+
+				if Args.Namespace == "" && len(names) == 1 {
+					if namespace, _ := importNameParts(names[0]); namespace != "" {
+						Args.Namespace = namespace
 					}
 				}
 
@@ -181,14 +261,18 @@ var importReleaseCmd = &cobra.Command{
 			},
 		},
 		ui.Arg[string]{
-			Name:     "namespace",
-			Ptr:      &Args.Namespace,
-			Disabled: types.FutureFromPtr(&Args.ImportCmd.All),
+			Name:      "namespace",
+			Ptr:       &Args.Namespace,
+			DependsOn: []string{"name"},
+			// several releases are given as namespace/name instead
+			Disabled: types.FutureFromFunc(func() bool {
+				return Args.ImportCmd.All || Args.Selector != "" || len(Args.Names) != 1
+			}),
 			Validator: types.MultiValidator(
 				types.OptionalEmptyValidator[string](),
 				types.EqualValidator(
 					types.StringerFunc(func() string {
-						return fmt.Sprintf("The release \"%s\" in namespace \"%s\" was not found in the cluster", Args.Name, "%s")
+						return fmt.Sprintf("The release \"%s\" in namespace \"%s\" was not found in the cluster", importTarget(), "%s")
 					}),
 					types.FutureFromFuncErr(func() ([]string, error) {
 						values, err := HelmReleaseChartFuture.Get()
@@ -198,7 +282,7 @@ var importReleaseCmd = &cobra.Command{
 
 						ret := []string{}
 						for _, v := range values {
-							if v.Name != Args.Name {
+							if v.Name != importTarget() {
 								continue
 							}
 
@@ -218,7 +302,7 @@ var importReleaseCmd = &cobra.Command{
 
 				idx := 0
 				for _, v := range releases {
-					if v.Name != Args.Name {
+					if v.Name != importTarget() {
 						continue
 					}
 
@@ -244,7 +328,7 @@ var importReleaseCmd = &cobra.Command{
 
 				ret := []types.Selectable{}
 				for _, v := range releases {
-					if v.Name != Args.Name {
+					if v.Name != importTarget() {
 						continue
 					}
 
@@ -254,7 +338,6 @@ var importReleaseCmd = &cobra.Command{
 				return ret, nil
 			})),
 			Callback: func(name string) error {
-				Args.Name = strings.ToLower(Args.Name)
 				Args.Namespace = strings.ToLower(Args.Namespace)
 				return nil
 			},
@@ -271,7 +354,10 @@ var importReleaseCmd = &cobra.Command{
 		}
 
 		importRelease := func(release HelmReleaseChart) {
-			releaseValues, err := external.Helm.GetReleaseValues(release.HelmRelease)
+			ctx, cancel := OperationContext(types.TimeoutQuery)
+			defer cancel()
+
+			releaseValues, err := external.Helm.GetReleaseValues(ctx, release.HelmRelease)
 			if err != nil {
 				logger.Fatalf("Could not get release values: %s", err)
 			}
@@ -314,10 +400,28 @@ var importReleaseCmd = &cobra.Command{
 				logger.Info("Dry run, not writing manifest or release file")
 			}
 
+			Audit(types.AuditEntry{
+				Action:     types.AuditActionImport,
+				Kind:       "release",
+				Name:       release.Name,
+				Namespace:  release.Namespace,
+				NewVersion: release.Chart.Version,
+				ValuesHash: ValuesHash(result.EnvSubbedValues),
+			}, nil)
+
 			logger.Infof("Successfully imported release \"%s\" from namespace \"%s\"", release.Name, release.Namespace)
 		}
 
 		if Args.ImportCmd.All {
+			charts := []types.HelmChart{}
+			for _, v := range releases {
+				if _, idx := Manifest.ReleaseIdxByName(v.Name); idx == -1 {
+					charts = append(charts, v.Chart.HelmChart)
+				}
+			}
+
+			PrefetchChartValues(charts)
+
 			for _, v := range releases {
 				if _, idx := Manifest.ReleaseIdxByName(v.Name); idx == -1 {
 					importRelease(v)
@@ -326,20 +430,133 @@ var importReleaseCmd = &cobra.Command{
 				}
 			}
 		} else {
-			var release HelmReleaseChart
-			for _, v := range releases {
-				if v.Name == Args.Name && strings.ToLower(v.Namespace) == Args.Namespace {
-					release = v
-					break
+			selected := selectedImportReleases(releases)
+			if len(selected) > 1 {
+				charts := make([]types.HelmChart, len(selected))
+				for i, v := range selected {
+					charts[i] = v.Chart.HelmChart
 				}
+
+				PrefetchChartValues(charts)
+			}
+
+			for _, release := range selected {
+				importRelease(release)
+			}
+		}
+	},
+}
+
+var importSingleCmd = &cobra.Command{
+	Use:     "single",
+	Short:   "Import resources from a cluster as a single",
+	Long:    "Import resources created outside of helm-manager from a cluster as a single, fields populated by the cluster are removed",
+	Example: "   helm-manager import single [NAME] [KIND] [RESOURCE]\n   helm-manager import single ingresses ingress -n default\n   helm-manager import single app-config configmap -n app -l app=web",
+	Args: ui.PositionalArgs([]ui.RequiredArg{
+		ui.Arg[string]{
+			Name:       "name",
+			Ptr:        &Args.Name,
+			Positional: true,
+			Validator: types.MultiValidator(
+				types.NameValidator("name", false),
+				types.NotEqualValidator(
+					types.ToStringer(`"%s" is already used in the manifest`),
+					types.FutureFromStringers(types.FutureFromPtr(&Manifest.Singles)),
+				),
+			),
+			UI: ui.PromptUiFunc[string]("Name"),
+			Callback: func(s string) error {
+				Args.Name = strings.ToLower(s)
+				return nil
+			},
+		},
+		ui.Arg[string]{
+			Name:       "kind",
+			Ptr:        &Args.ImportCmd.Kind,
+			Positional: true,
+			Validator:  types.EmptyValidator[string]("kind", false),
+			UI:         ui.PromptUiFunc[string]("Kind"),
+		},
+		ui.Arg[string]{
+			Name:       "resource",
+			Ptr:        &Args.ImportCmd.Resource,
+			Positional: true,
+		},
+	}, func(cmd *cobra.Command) {
+		zap.S().Infof("* %s *", color.MagentaString("Helm Manager Import Single"))
+
+		ManifestExist(cmd)
+	}),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !Args.Force {
+			if _, err := os.Stat(SinglePath(Args.Name)); err == nil {
+				logger.Fatal("Single file already exists, use --force to overwrite")
 			}
+		}
 
-			if release.Name == "" {
-				logger.Fatalf("Could not find release \"%s\" in namespace \"%s\"", Args.Name, Args.Namespace)
+		done := utils.Loader(utils.LoaderOptions{
+			FetchingText: fmt.Sprintf("Fetching %s from the cluster", Args.ImportCmd.Kind),
+			SuccessText:  fmt.Sprintf("Fetched %s from the cluster", Args.ImportCmd.Kind),
+			FailureText:  fmt.Sprintf("Failed to fetch %s from the cluster", Args.ImportCmd.Kind),
+		})
+		ctx, cancel := OperationContext(types.TimeoutQuery)
+		defer cancel()
+
+		data, err := external.Kubectl.GetResources(ctx, Args.ImportCmd.Kind, Args.Namespace, Args.ImportCmd.Resource, Args.ImportCmd.Selector)
+		done(err == nil)
+		if err != nil {
+			logger.Fatalf("Could not get resources: %s\n%s", err, data)
+		}
+
+		resources, err := utils.ParseKubeResources(data)
+		if err != nil {
+			logger.Fatalf("Could not parse resources: %s", err)
+		}
+
+		if len(resources) == 0 {
+			logger.Fatal("No resources matched")
+		}
+
+		for _, resource := range resources {
+			if resource.Label("app.kubernetes.io/managed-by") == "Helm" {
+				logger.Warnf("%s %s is managed by helm, import its release instead", resource.Kind(), resource.Name())
 			}
 
-			importRelease(release)
+			utils.CleanKubeResource(resource)
+			logger.Infof("Importing %s %s", resource.Kind(), resource.Name())
+		}
+
+		values, err := utils.MarshalKubeResources(resources)
+		if err != nil {
+			logger.Fatalf("Could not marshal resources: %s", err)
+		}
+
+		single := types.ManifestSingle{
+			Name:      Args.Name,
+			Namespace: Args.Namespace,
 		}
+
+		Manifest.Singles = append(Manifest.Singles, single)
+
+		if !Args.DryRun {
+			if err = os.WriteFile(SinglePath(single.Name), values, 0644); err != nil {
+				logger.Fatalf("failed to write single file: %s", err)
+			}
+			utils.WriteManifest(Args.Context)
+		} else {
+			logger.Info("Dry run, not writing manifest or single file")
+			zap.S().Info(string(values))
+		}
+
+		Audit(types.AuditEntry{
+			Action:     types.AuditActionImport,
+			Kind:       "single",
+			Name:       single.Name,
+			Namespace:  single.Namespace,
+			ValuesHash: ValuesHash(values),
+		}, nil)
+
+		logger.Infof("Successfully imported %d resources into single \"%s\"", len(resources), single.Name)
 	},
 }
 
@@ -452,6 +669,222 @@ var importRepoCmd = &cobra.Command{
 			logger.Info("Dry run, not writing manifest")
 		}
 
+		Audit(types.AuditEntry{
+			Action: types.AuditActionImport,
+			Kind:   "repo",
+			Name:   repo.Name,
+		}, nil)
+
 		logger.Infof("Successfully imported repo \"%s\"", repo.Name)
 	},
 }
+
+// releaseImporter collects repos and releases translated from another tool, so they can be written together once everything was read.
+// Anything which cannot be translated is reported instead of failing the whole import.
+type releaseImporter struct {
+	skipped  []string
+	releases []types.ManifestRelease
+	values   map[string][]byte
+	versions map[string]types.HelmChartMulti
+	charts   []types.HelmChartMulti
+}
+
+func newReleaseImporter() *releaseImporter {
+	return &releaseImporter{
+		values:   map[string][]byte{},
+		versions: map[string]types.HelmChartMulti{},
+	}
+}
+
+func (r *releaseImporter) report(format string, args ...any) {
+	r.skipped = append(r.skipped, fmt.Sprintf(format, args...))
+}
+
+// addRepo adds a repo to the manifest unless one with the same name or url exists, it returns the name used in the manifest.
+func (r *releaseImporter) addRepo(name string, url string) string {
+	name = strings.ToLower(name)
+
+	for _, repo := range Manifest.Repos {
+		if strings.TrimSuffix(repo.URL, "/") == strings.TrimSuffix(url, "/") {
+			return repo.Name
+		}
+	}
+
+	if existing := Manifest.RepoByName(name); existing.Name != "" {
+		r.report("repository %s: already in the manifest with url %s, keeping it", name, existing.URL)
+		return existing.Name
+	}
+
+	Manifest.Repos = append(Manifest.Repos, types.ManifestRepo{
+		Name: name,
+		URL:  url,
+	})
+
+	return name
+}
+
+// loadCharts adds the repos to helm so the charts of the imported releases can be found.
+func (r *releaseImporter) loadCharts() {
+	if !Args.DryRun {
+		deployReposHelper()
+	} else {
+		logger.Info("Dry run, not adding repos, charts are only found in repos which already exist")
+	}
+
+	charts, err := HelmChartsFuture.Get()
+	if err != nil {
+		logger.Fatalf("failed to get helm charts: %s", err)
+	}
+
+	r.charts = charts
+}
+
+// skipRelease reports if a release cannot be imported under name, because it exists already.
+func (r *releaseImporter) skipRelease(name string) bool {
+	name = strings.ToLower(name)
+
+	if _, idx := Manifest.ReleaseIdxByName(name); idx != -1 || r.versions[name].RepoName != "" {
+		r.report("release %s: already in the manifest, skipping", name)
+		return true
+	}
+
+	if !Args.Force {
+		if _, err := os.Stat(ReleasePath(name)); err == nil {
+			r.report("release %s: release file already exists, use --force to overwrite, skipping", name)
+			return true
+		}
+	}
+
+	return false
+}
+
+// addRelease resolves the chart of a release and queues it for writing, values is the yaml of the values section.
+func (r *releaseImporter) addRelease(name string, namespace string, repo string, chartName string, version string, values []byte) {
+	name = strings.ToLower(name)
+
+	if r.skipRelease(name) {
+		return
+	}
+
+	multiChart := types.HelmChartMultiArray(r.charts).FindChart(fmt.Sprintf("%s/%s", repo, chartName))
+	if multiChart.RepoName == "" {
+		r.report("release %s: chart %s/%s was not found, skipping", name, repo, chartName)
+		return
+	}
+
+	if version == "" {
+		r.report("release %s: no version pinned, using the latest version %s", name, multiChart.Version)
+		version = multiChart.Version
+	}
+
+	chart := multiChart.FindVersion(version)
+	if chart.Version == "" {
+		r.report("release %s: version %s of %s/%s was not found, version ranges are not supported, skipping", name, version, repo, chartName)
+		return
+	}
+
+	multiChart.HelmChart = types.HelmChart(chart)
+
+	if namespace == "" {
+		r.report("release %s: no namespace set, using default", name)
+		namespace = "default"
+	}
+
+	r.releases = append(r.releases, types.ManifestRelease{
+		Name:      name,
+		Namespace: strings.ToLower(namespace),
+		Chart: types.ManifestChart{
+			Name:       chartName,
+			Version:    chart.Version,
+			AppVersion: chart.AppVersion,
+			Repo:       repo,
+		},
+	})
+	r.values[name] = values
+	r.versions[name] = multiChart
+}
+
+// dependsOn records dependencies between imported releases, dependencies on anything not imported in the same run are reported.
+func (r *releaseImporter) dependsOn(name string, deps []string) {
+	name = strings.ToLower(name)
+
+	idx := -1
+	for i, release := range r.releases {
+		if release.Name == name {
+			idx = i
+		}
+	}
+
+	if idx == -1 {
+		return
+	}
+
+	for _, dep := range deps {
+		dep = strings.ToLower(dep)
+		if r.versions[dep].RepoName == "" {
+			r.report("release %s: depends on %s which was not imported", name, dep)
+			continue
+		}
+
+		r.releases[idx].DependsOn = append(r.releases[idx].DependsOn, types.EntryKindRelease+"/"+dep)
+	}
+}
+
+// labels sets the labels of an imported release.
+func (r *releaseImporter) labels(name string, labels map[string]string) {
+	name = strings.ToLower(name)
+
+	for i, release := range r.releases {
+		if release.Name == name && len(labels) != 0 {
+			r.releases[i].Labels = labels
+		}
+	}
+}
+
+// write generates the release files through UpgradeDocument and writes them along with the manifest.
+func (r *releaseImporter) write(total int) {
+	Manifest.Releases = append(Manifest.Releases, r.releases...)
+
+	charts := make([]types.HelmChart, 0, len(r.releases))
+	for _, release := range r.releases {
+		charts = append(charts, r.versions[release.Name].HelmChart)
+	}
+
+	PrefetchChartValues(charts)
+
+	for _, release := range r.releases {
+		result, err := UpgradeDocument(r.values[release.Name], r.versions[release.Name], true)
+		if err != nil {
+			logger.Fatalf("Could not upgrade document for %s: %s", release.Name, err)
+		}
+
+		if !Args.DryRun {
+			if err = os.WriteFile(ReleasePath(release.Name), result.Document, 0644); err != nil {
+				logger.Fatalf("failed to write release file: %s", err)
+			}
+		}
+
+		Audit(types.AuditEntry{
+			Action:     types.AuditActionImport,
+			Kind:       "release",
+			Name:       release.Name,
+			Namespace:  release.Namespace,
+			NewVersion: release.Chart.Version,
+			ValuesHash: ValuesHash(result.EnvSubbedValues),
+		}, nil)
+
+		logger.Infof("Imported release \"%s\" from namespace \"%s\"", release.Name, release.Namespace)
+	}
+
+	if !Args.DryRun {
+		utils.WriteManifest(Args.Context)
+	} else {
+		logger.Info("Dry run, not writing manifest or release files")
+	}
+
+	for _, msg := range r.skipped {
+		logger.Warn(msg)
+	}
+
+	logger.Infof("Imported %d of %d releases, %d items could not be translated", len(r.releases), total, len(r.skipped))
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&Args.LockWait, "lock-wait", "", "How long to wait for the cluster lock held by someone else, eg. 5m (defaults to lease.wait of the manifest)")
+
+	rootCmd.AddCommand(unlockCmd)
+	unlockCmd.Flags().BoolVarP(&Args.Force, "force", "", false, "Release the lock even if it is held by someone else")
+}
+
+// leaseUser is who holds a lease, without the process id.
+func leaseUser() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s@%s", auditUserFuture.GetOrPanic(), utils.OrStr(host, "unknown"))
+}
+
+func leaseHolder() string {
+	return fmt.Sprintf("%s:%d", leaseUser(), os.Getpid())
+}
+
+func leaseBackend() types.LeaseBackend {
+	return external.KubeLeaseBackend{
+		Name:      Manifest.Lease.NameOrDefault(),
+		Namespace: Manifest.Lease.NamespaceOrDefault(),
+	}
+}
+
+var heldLease *utils.HeldLease
+
+// ClusterLeaseFuture locks the cluster for this process, it is resolved before the first change to the cluster.
+// Dry runs do not change anything so they do not take the lock.
+var ClusterLeaseFuture = types.FutureFromFuncErr(func() (bool, error) {
+	if Manifest.Lease.Disabled || Args.DryRun {
+		return false, nil
+	}
+
+	ttl, err := Manifest.Lease.TTLDuration()
+	if err != nil {
+		return false, fmt.Errorf("invalid lease ttl, %v", err)
+	} else if ttl <= 0 {
+		return false, fmt.Errorf("invalid lease ttl, %s is not positive", ttl)
+	}
+
+	wait, err := Manifest.Lease.WaitDuration()
+	if Args.LockWait != "" {
+		wait, err = time.ParseDuration(Args.LockWait)
+	}
+	if err != nil {
+		return false, fmt.Errorf("invalid lock wait, %v", err)
+	}
+
+	done := utils.Loader(utils.LoaderOptions{
+		FetchingText: "Locking cluster",
+		SuccessText:  "Locked cluster",
+		FailureText:  "Failed to lock cluster",
+	})
+
+	// every attempt runs a few kubectl commands, so give them room on top of the wait
+	queryTimeout, _ := Manifest.Timeouts.Duration(types.TimeoutQuery)
+	ctx, cancel := context.WithTimeout(RootContext, wait+queryTimeout)
+	defer cancel()
+
+	lease, err := utils.AcquireLease(ctx, leaseBackend(), utils.LeaseRequest{
+		Holder:   leaseHolder(),
+		Manifest: Manifest.Name,
+		TTL:      ttl,
+		Wait:     wait,
+	})
+	done(err == nil)
+
+	var held utils.LeaseHeldError
+	if errors.As(err, &held) {
+		return false, fmt.Errorf("%v\n   wait for it to finish, retry with `--lock-wait 10m` or if it is stale run `%s`", err, color.YellowString("helm-manager unlock --force"))
+	} else if err != nil {
+		return false, fmt.Errorf("failed to lock cluster: %v", err)
+	}
+
+	heldLease = lease
+	lease.KeepAlive(RootContext, queryTimeout, func(err error) {
+		logger.Warnf("failed to renew cluster lock: %s", err)
+	})
+	logger.OnExit(UnlockCluster)
+
+	return true, nil
+})
+
+// LockCluster takes the cluster lock if this process does not hold it yet.
+func LockCluster() error {
+	_, err := ClusterLeaseFuture.Get()
+	return err
+}
+
+// UnlockCluster releases the cluster lock if this process holds it.
+func UnlockCluster() {
+	if heldLease == nil {
+		return
+	}
+
+	ctx, cancel := cleanupContext()
+	defer cancel()
+
+	if err := heldLease.Release(ctx); err != nil {
+		logger.Warnf("failed to release cluster lock: %s", err)
+	}
+
+	heldLease = nil
+}
+
+var unlockCmd = &cobra.Command{
+	Use:     "unlock",
+	Short:   "Release the cluster lock",
+	Long:    "Release the cluster lock left behind by a deploy which did not finish, locks held by someone else are only released with --force",
+	Example: "   helm-manager unlock\n   helm-manager unlock --force",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		zap.S().Infof("* %s *", color.RedString("Helm Manager Unlock"))
+
+		ManifestExist(cmd)
+
+		ctx, cancel := OperationContext(types.TimeoutQuery)
+		defer cancel()
+
+		backend := leaseBackend()
+		lease, exists, err := backend.Get(ctx)
+		if err != nil {
+			logger.Fatalf("failed to get cluster lock: %s", err)
+		}
+
+		if !exists {
+			logger.Info("cluster is not locked")
+			return
+		}
+
+		logger.Infof("cluster is locked by %s", lease)
+
+		mine := strings.HasPrefix(lease.Holder, leaseUser()+":")
+		if !mine && !lease.Expired(time.Now()) && !Args.Force {
+			logger.Fatalf("the lock is held by someone else, use --force to release it anyway")
+		}
+
+		if Args.DryRun {
+			logger.Info("Dry run, not releasing the lock")
+			return
+		}
+
+		err = backend.Delete(ctx, lease)
+		Audit(types.AuditEntry{
+			Action: types.AuditActionUnlock,
+			Kind:   "lease",
+			Name:   Manifest.Lease.NameOrDefault(),
+		}, err)
+		if err != nil {
+			logger.Fatalf("failed to release cluster lock: %s", err)
+		}
+
+		logger.Infof("released the cluster lock of %s", lease.Holder)
+	},
+}
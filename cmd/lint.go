@@ -0,0 +1,415 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/redact"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&Args.Output, "output", "o", "text", "Output format, text or json")
+}
+
+var (
+	yamlLineRegex = regexp.MustCompile(`line (\d+):?`)
+	envRefRegex   = regexp.MustCompile(`\$\{([^}]*)\}`)
+)
+
+type linter struct {
+	diags types.Diagnostics
+	wd    string
+}
+
+func (l *linter) displayPath(file string) string {
+	if rel, err := filepath.Rel(l.wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+
+	return file
+}
+
+func (l *linter) add(severity types.Severity, file string, node *yaml.Node, format string, args ...any) {
+	diag := types.Diagnostic{
+		Severity: severity,
+		File:     l.displayPath(file),
+		Message:  fmt.Sprintf(format, args...),
+	}
+
+	if node != nil {
+		diag.Line = node.Line
+		diag.Column = node.Column
+	}
+
+	l.diags = append(l.diags, diag)
+}
+
+// addYamlErr converts errors from the yaml parser into diagnostics, the parser only reports lines as part of the message.
+func (l *linter) addYamlErr(file string, err error) {
+	msgs := []string{err.Error()}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	}
+
+	for _, msg := range msgs {
+		diag := types.Diagnostic{
+			Severity: types.SeverityError,
+			File:     l.displayPath(file),
+			Message:  strings.TrimPrefix(msg, "yaml: "),
+		}
+
+		if match := yamlLineRegex.FindStringSubmatch(msg); match != nil {
+			diag.Line, _ = strconv.Atoi(match[1])
+			diag.Message = strings.TrimSpace(strings.Replace(diag.Message, match[0], "", 1))
+		}
+
+		l.diags = append(l.diags, diag)
+	}
+}
+
+func (l *linter) lintEnvRefs(file string, data []byte, allowed map[string]bool) {
+	for i, line := range strings.Split(string(data), "\n") {
+		for _, match := range envRefRegex.FindAllStringSubmatchIndex(line, -1) {
+			env := line[match[2]:match[3]]
+			if allowed[env] {
+				continue
+			}
+
+			l.diags = append(l.diags, types.Diagnostic{
+				Severity: types.SeverityError,
+				File:     l.displayPath(file),
+				Line:     i + 1,
+				Column:   match[0] + 1,
+				Message:  fmt.Sprintf("env variable \"%s\" is not in allowed_env", env),
+			})
+		}
+	}
+}
+
+// lintValueEnvRefs checks the env variables used in a values document, only values are substituted on deploy
+// so references in comments or the chart defaults are left alone.
+func (l *linter) lintValueEnvRefs(file string, values *yaml.Node, allowed map[string]bool) {
+	utils.TraverseYamlNode(values, func(node *yaml.Node) {
+		if node.Kind != yaml.ScalarNode {
+			return
+		}
+
+		for _, match := range envRefRegex.FindAllStringSubmatch(node.Value, -1) {
+			if !allowed[match[1]] {
+				l.add(types.SeverityError, file, node, "env variable \"%s\" is not in allowed_env", match[1])
+			}
+		}
+	})
+}
+
+func (l *linter) lintReleaseFile(file string, data []byte, release types.ManifestRelease, allowed map[string]bool) {
+	node, err := utils.ParseYaml(data)
+	if err != nil {
+		l.addYamlErr(file, err)
+		return
+	}
+
+	switch len(node.Content) {
+	case 3:
+		l.lintValueEnvRefs(file, node.Content[1], allowed)
+	case 1:
+		l.add(types.SeverityWarning, file, nil, "release file only contains values, run `helm-manager deploy release %s` to generate the lock and defaults", release.Name)
+		l.lintValueEnvRefs(file, node.Content[0], allowed)
+		return
+	default:
+		l.add(types.SeverityError, file, nil, "release file must contain 3 documents (lock, values and defaults), found %d", len(node.Content))
+		return
+	}
+
+	lockNode := node.Content[0]
+	lockData, err := yaml.Marshal(lockNode)
+	if err != nil {
+		l.add(types.SeverityError, file, lockNode, "failed to read lock, %v", err)
+		return
+	}
+
+	lock := types.ReleaseLock{}
+	if err := utils.DecodeStrict(lockData, &lock); err != nil {
+		l.add(types.SeverityError, file, lockNode, "invalid lock, %v", err)
+	} else if lock.Chart != release.Chart.RepoName() || lock.Version != release.Chart.Version {
+		l.add(types.SeverityWarning, file, lockNode, "lock (%s %s) does not match the manifest (%s %s), run `helm-manager deploy release %s` to regenerate it", lock.Chart, lock.Version, release.Chart.RepoName(), release.Chart.Version, release.Name)
+	}
+
+	for i, name := range []string{"values", "defaults"} {
+		if doc := node.Content[i+1]; doc.Kind != yaml.MappingNode {
+			l.add(types.SeverityError, file, doc, "%s section must be a mapping", name)
+		}
+	}
+}
+
+func (l *linter) lintOrphans(dir string, known map[string]bool, kind string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		file := path.Join(dir, entry.Name())
+		if entry.IsDir() || path.Ext(file) != ".yaml" || known[file] {
+			continue
+		}
+
+		l.add(types.SeverityWarning, file, nil, "%s file is not referenced by the manifest", kind)
+	}
+}
+
+// lintPolicy checks the policy file and the waivers of the manifest against it.
+func (l *linter) lintPolicy(manifestPath string, root *yaml.Node, manifest types.Manifest) {
+	file := PolicyPath()
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		l.add(types.SeverityError, file, nil, "failed to read policy, %v", err)
+		return
+	}
+
+	policy := types.Policy{}
+	policyRoot := &yaml.Node{}
+	if err == nil {
+		if err := yaml.Unmarshal(data, policyRoot); err != nil {
+			l.addYamlErr(file, err)
+			return
+		}
+
+		if err := utils.DecodeStrict(data, &policy); err != nil {
+			l.addYamlErr(file, err)
+			return
+		}
+
+		for _, diag := range policy.Validate() {
+			l.add(diag.Severity, file, utils.NodeAtPath(policyRoot, diag.Field), "%s", diag.Message)
+		}
+	}
+
+	now := time.Now()
+	lintWaivers := func(field string, name string, waivers types.ManifestWaivers) {
+		for i, waiver := range waivers {
+			waiverField := fmt.Sprintf("%s.waivers[%d]", field, i)
+			if waiver.Rule != "" && policy.RuleByName(waiver.Rule).Name == "" {
+				l.add(types.SeverityWarning, manifestPath, utils.NodeAtPath(root, waiverField+".rule"), "\"%s\" waives rule \"%s\" which is not in the policy", name, waiver.Rule)
+			}
+
+			if _, err := waiver.ExpiresAt(); err == nil && waiver.Expired(now) {
+				l.add(types.SeverityWarning, manifestPath, utils.NodeAtPath(root, waiverField+".expires"), "waiver of rule \"%s\" for \"%s\" expired on %s", waiver.Rule, name, waiver.Expires)
+			}
+		}
+	}
+
+	for i, release := range manifest.Releases {
+		lintWaivers(fmt.Sprintf("releases[%d]", i), release.Name, release.Waivers)
+	}
+
+	for i, single := range manifest.Singles {
+		lintWaivers(fmt.Sprintf("singles[%d]", i), single.Name, single.Waivers)
+	}
+}
+
+func Lint() types.Diagnostics {
+	wd, _ := os.Getwd()
+	l := &linter{wd: wd}
+
+	manifestPath := utils.ManifestPath(Args.Context)
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		l.add(types.SeverityError, manifestPath, nil, "failed to read manifest, %v", err)
+		return l.diags
+	}
+
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(data, root); err != nil {
+		l.addYamlErr(manifestPath, err)
+		return l.diags
+	}
+
+	from, err := utils.MigrateManifestNode(root)
+	if err != nil {
+		l.add(types.SeverityError, manifestPath, utils.MappingValue(root, "apiVersion"), "%v", err)
+		return l.diags
+	}
+
+	if from != types.ManifestAPIVersion {
+		l.add(types.SeverityWarning, manifestPath, nil, "manifest uses apiVersion %s, run `helm-manager migrate` to upgrade it to %s", from, types.ManifestAPIVersion)
+
+		if data, err = yaml.Marshal(root); err != nil {
+			l.add(types.SeverityError, manifestPath, nil, "failed to marshal migrated manifest, %v", err)
+			return l.diags
+		}
+	}
+
+	manifest := types.Manifest{}
+	if err := utils.DecodeStrict(data, &manifest); err != nil {
+		l.addYamlErr(manifestPath, err)
+
+		// continue with whatever could be decoded so every other problem is still reported
+		manifest = types.Manifest{}
+		if err := root.Decode(&manifest); err != nil {
+			return l.diags
+		}
+	}
+
+	for _, diag := range manifest.Validate() {
+		node := utils.NodeAtPath(root, diag.Field)
+		l.add(diag.Severity, manifestPath, node, "%s", diag.Message)
+	}
+
+	allowedEnv := map[string]bool{
+		"HELM_MANAGER_NAME":         true,
+		"HELM_MANAGER_CONTEXT_NAME": true,
+	}
+	for _, env := range manifest.AllowedEnv {
+		allowedEnv[env.String()] = true
+	}
+
+	localCharts := map[string]bool{}
+	for i, pth := range manifest.LocalCharts {
+		chart := types.HelmChart{
+			IsLocal:   true,
+			LocalPath: utils.MergeRelativePath(Args.Context, pth.String()),
+		}
+
+		if err := utils.ParseLocalChartYaml(&chart); err != nil {
+			l.add(types.SeverityError, manifestPath, utils.NodeAtPath(root, fmt.Sprintf("local_charts[%d]", i)), "failed to parse %s, %v", path.Join(pth.String(), "Chart.yaml"), err)
+			continue
+		}
+
+		localCharts[chart.RepoName] = true
+	}
+
+	if manifest.Keyring != "" {
+		if _, err := os.Stat(utils.MergeRelativePath(Args.Context, manifest.Keyring)); err != nil {
+			l.add(types.SeverityError, manifestPath, utils.NodeAtPath(root, "keyring"), "keyring cannot be read, %v", err)
+		}
+	}
+
+	knownReleases := map[string]bool{}
+	for i, release := range manifest.Releases {
+		if release.Name == "" {
+			continue
+		}
+
+		if release.Chart.Repo == "" && !localCharts[release.Chart.Name] {
+			l.add(types.SeverityError, manifestPath, utils.NodeAtPath(root, fmt.Sprintf("releases[%d].chart.name", i)), "chart \"%s\" has no repo and is not a local chart in the manifest", release.Chart.Name)
+		}
+
+		if manifest.Vendor && release.Chart.Repo != "" {
+			if _, err := os.Stat(VendorPath(release.Chart)); err != nil {
+				l.add(types.SeverityError, manifestPath, utils.NodeAtPath(root, fmt.Sprintf("releases[%d].chart.version", i)), "chart %s %s is not vendored, run `helm-manager vendor`", release.Chart.RepoName(), release.Chart.Version)
+			}
+		}
+
+		file := ReleasePath(release.Name)
+		knownReleases[file] = true
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			l.add(types.SeverityError, manifestPath, utils.NodeAtPath(root, fmt.Sprintf("releases[%d].name", i)), "release file %s cannot be read, %v", l.displayPath(file), err)
+			continue
+		}
+
+		l.lintReleaseFile(file, data, release, allowedEnv)
+	}
+
+	knownSingles := map[string]bool{}
+	for i, single := range manifest.Singles {
+		if single.Name == "" {
+			continue
+		}
+
+		file := SinglePath(single.Name)
+		knownSingles[file] = true
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			l.add(types.SeverityError, manifestPath, utils.NodeAtPath(root, fmt.Sprintf("singles[%d].name", i)), "single file %s cannot be read, %v", l.displayPath(file), err)
+			continue
+		}
+
+		if _, err := utils.ParseYaml(data); err != nil {
+			l.addYamlErr(file, err)
+		}
+
+		l.lintEnvRefs(file, data, allowedEnv)
+	}
+
+	l.lintPolicy(manifestPath, root, manifest)
+
+	l.lintOrphans(path.Join(Args.Context, "releases"), knownReleases, "release")
+	l.lintOrphans(path.Join(Args.Context, "singles"), knownSingles, "single")
+
+	return l.diags
+}
+
+var lintCmd = &cobra.Command{
+	Use:         "lint",
+	Short:       "Check the manifest, release and single files for problems",
+	Long:        "Check the manifest, release and single files for problems, without talking to the cluster",
+	Example:     "   helm-manager lint\n   helm-manager lint -o json",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{AnnotationOffline: "true"},
+	Run: func(cmd *cobra.Command, _ []string) {
+		if Args.Output != "text" && Args.Output != "json" {
+			logger.Fatalf("unknown output format \"%s\", must be text or json", Args.Output)
+		}
+
+		if !Manifest.Exists && ManifestErr == nil {
+			ManifestExist(cmd)
+		}
+
+		diags := Lint()
+
+		if Args.Output == "json" {
+			if diags == nil {
+				diags = types.Diagnostics{}
+			}
+
+			enc := json.NewEncoder(redact.Writer(os.Stdout))
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(diags); err != nil {
+				logger.Fatalf("failed to encode diagnostics: %s", err)
+			}
+
+			if diags.HasErrors() {
+				os.Exit(1)
+			}
+
+			return
+		}
+
+		zap.S().Infof("* %s *", color.BlueString("Helm Manager Lint"))
+
+		counts := map[types.Severity]int{}
+		for _, diag := range diags {
+			counts[diag.Severity]++
+			zap.S().Infof("%s: %s: %s", color.New(color.Bold).Sprint(diag.Location()), diag.Severity.Colored(), diag.Message)
+		}
+
+		if diags.HasErrors() {
+			logger.Fatalf("%d errors, %d warnings", counts[types.SeverityError], counts[types.SeverityWarning])
+		}
+
+		logger.Infof("%d errors, %d warnings", counts[types.SeverityError], counts[types.SeverityWarning])
+	},
+}
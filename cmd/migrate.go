@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func BackupPath(name string) string {
+	return path.Join(Args.Context, ".backup", name)
+}
+
+func backupFile(backupDir string, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(Args.Context, file)
+	if err != nil {
+		return err
+	}
+
+	dst := path.Join(backupDir, rel)
+	if err := os.MkdirAll(path.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, data, 0644)
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the manifest to the latest apiVersion",
+	Long:  "Upgrade the manifest to the latest apiVersion, a backup of the old manifest is written to the .backup directory. Other commands upgrade the manifest as well when they write it, without a backup",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		zap.S().Infof("* %s *", color.BlueString("Helm Manager Migrate"))
+
+		ManifestExist(cmd)
+
+		from := Manifest.SourceAPIVersion
+		if from == types.ManifestAPIVersion {
+			logger.Infof("manifest is already at apiVersion %s", types.ManifestAPIVersion)
+			return
+		}
+
+		chain, err := utils.MigrationsFrom(from)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		for _, migration := range chain {
+			logger.Infof("migrating %s -> %s", color.RedString(migration.From), color.GreenString(migration.To))
+		}
+
+		if Args.DryRun {
+			logger.Info("Dry run, would migrate the manifest")
+			return
+		}
+
+		backupDir := BackupPath(strings.ReplaceAll(from, "/", "-") + "-" + time.Now().Format("20060102-150405"))
+		if err := backupFile(backupDir, utils.ManifestPath(Args.Context)); err != nil {
+			logger.Fatalf("failed to backup manifest: %s", err)
+		}
+
+		Manifest.SourceAPIVersion = types.ManifestAPIVersion
+		utils.WriteManifest(Args.Context)
+
+		logger.Infof("manifest migrated to %s, backup written to %s", color.GreenString(types.ManifestAPIVersion), backupDir)
+	},
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func init() {
+	{
+		deployCmd.AddCommand(deployNamespaceCmd)
+	}
+
+	{
+		removeCmd.AddCommand(removeNamespaceCmd)
+		removeNamespaceCmd.Flags().StringVar(&Args.Name, "name", "", "Name of the namespace")
+		removeNamespaceCmd.Flags().BoolVar(&Args.Deploy, "deploy", false, "Delete the namespace from the cluster")
+	}
+}
+
+func applyNamespace(ns types.ManifestNamespace) error {
+	data, err := utils.MarshalKubeResources(utils.NamespaceResources(ns))
+	if err != nil {
+		return fmt.Errorf("failed to marshal namespace %s, %v", ns.Name, err)
+	}
+
+	done := utils.Loader(utils.LoaderOptions{
+		FetchingText: fmt.Sprintf("Applying namespace %s", ns.Name),
+		SuccessText:  fmt.Sprintf("Applied namespace %s", ns.Name),
+		FailureText:  fmt.Sprintf("Failed to apply namespace %s", ns.Name),
+	})
+
+	ctx, cancel := OperationContext(types.TimeoutDeploy)
+	defer cancel()
+
+	resp, err := external.Kubectl.Deploy(ctx, data, "", false, Args.DryRun)
+	done(err == nil)
+	if err != nil {
+		err = fmt.Errorf("failed to apply namespace %s: %v\n%s", ns.Name, err, resp)
+	}
+
+	Audit(types.AuditEntry{
+		Action:     types.AuditActionDeploy,
+		Kind:       "namespace",
+		Name:       ns.Name,
+		ValuesHash: ValuesHash(data),
+	}, err)
+
+	return err
+}
+
+// ApplyNamespacesFuture applies the managed namespaces of the manifest, it is resolved before the first release or single is deployed.
+var ApplyNamespacesFuture = types.FutureFromFuncErr(func() (bool, error) {
+	if err := LockCluster(); err != nil {
+		return false, err
+	}
+
+	for _, ns := range Manifest.Namespaces {
+		if !ns.Managed {
+			continue
+		}
+
+		if err := applyNamespace(ns); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+})
+
+// LiveNamespace fetches the namespace and the objects helm-manager creates in it, objects which do not exist are left out.
+func LiveNamespace(name string) ([]utils.KubeResource, error) {
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	data, err := external.Kubectl.GetObjects(ctx, name,
+		"namespace/"+name,
+		"resourcequota/"+utils.NamespaceObjectName,
+		"limitrange/"+utils.NamespaceObjectName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, data)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	return utils.ParseKubeResources(data)
+}
+
+var deployNamespaceCmd = &cobra.Command{
+	Use:   "namespace",
+	Short: "Apply the managed namespaces",
+	Long:  "Apply the labels, annotations, quotas and limit ranges of the managed namespaces in the manifest",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		zap.S().Infof("* %s *", color.BlueString("Helm Manager Deploy Namespace"))
+
+		ManifestExist(cmd)
+
+		if _, err := ApplyNamespacesFuture.Get(); err != nil {
+			logger.Fatal(err)
+		}
+
+		logger.Infof("namespaces deployed")
+	},
+}
+
+var removeNamespaceCmd = &cobra.Command{
+	Use:     "namespace",
+	Short:   "Remove a namespace from the manifest",
+	Long:    "Remove a namespace from the manifest, namespaces cannot be removed while releases or singles are installed in them",
+	Example: "helm-manager remove namespace [NAME]",
+	Args: ui.PositionalArgs([]ui.RequiredArg{
+		ui.Arg[string]{
+			Name:       "name",
+			Ptr:        &Args.Name,
+			Positional: true,
+			Validator:  types.EqualValidator(types.ToStringer(`"%s" is not a namespace in the manifest`), types.FutureFromStringers(types.FutureFromPtr(&Manifest.Namespaces))),
+			UI: ui.PromptUiSelectorFunc[string]("Namespace", "Which namespace do you want to remove?", func(i int) error {
+				Args.Name = Manifest.Namespaces[i].Name
+				return nil
+			}, types.FutureInterfacerArray[types.ManifestNamespace, types.Selectable](types.FutureFromPtr(&Manifest.Namespaces))),
+			Callback: func(s string) error {
+				Args.Name = strings.ToLower(s)
+
+				if users := Manifest.NamespaceUsers(s); len(users) != 0 {
+					names := make([]string, len(users))
+					for i, user := range users {
+						names[i] = user.String()
+					}
+
+					return fmt.Errorf("namespace %s is still used by %s", s, strings.Join(names, ", "))
+				}
+
+				return nil
+			},
+		},
+		ui.Arg[bool]{
+			Name:      "deploy",
+			Ptr:       &Args.Deploy,
+			DependsOn: []string{"name"},
+			Disabled: types.FutureFromFunc(func() bool {
+				return !Manifest.NamespaceByName(Args.Name).Managed
+			}),
+			UI: ui.PromptUiConfirmFunc("Do you want to delete the namespace and everything in it from the cluster", false),
+		},
+		ui.Arg[bool]{
+			Name: "confirm",
+			Ptr:  &Args.Confirm,
+			Disabled: types.FutureFromFunc(func() bool {
+				return !Args.Deploy || Args.DryRun
+			}),
+			UI: ui.PromptUiConfirmFunc("Are you sure you want to delete this namespace", false),
+			Callback: func(b bool) error {
+				if !b {
+					return fmt.Errorf("Aborted")
+				}
+
+				return nil
+			},
+		},
+	}, func(cmd *cobra.Command) {
+		zap.S().Infof("* %s *", color.RedString("Helm Manager Remove Namespace"))
+		ManifestExist(cmd)
+
+		if len(Manifest.Namespaces) == 0 {
+			logger.Fatal("no namespaces added to the manifest")
+		}
+	}),
+	Run: func(cmd *cobra.Command, _ []string) {
+		ns := Manifest.NamespaceByName(Args.Name)
+		for i, n := range Manifest.Namespaces {
+			if strings.ToLower(n.Name) == Args.Name {
+				Manifest.Namespaces = append(Manifest.Namespaces[:i], Manifest.Namespaces[i+1:]...)
+				break
+			}
+		}
+
+		if Args.Deploy {
+			if !ns.Managed {
+				logger.Fatalf("namespace %s is not managed by helm-manager, refusing to delete it", ns.Name)
+			}
+
+			if Args.DryRun {
+				logger.Info("Running in dry run mode, not actually deleting the namespace")
+			}
+
+			if err := LockCluster(); err != nil {
+				logger.Fatal(err)
+			}
+
+			data, err := utils.MarshalKubeResources(utils.NamespaceResources(types.ManifestNamespace{Name: ns.Name}))
+			if err != nil {
+				logger.Fatalf("failed to marshal namespace: %s", err)
+			}
+
+			done := utils.Loader(utils.LoaderOptions{
+				FetchingText: "Deleting namespace",
+				SuccessText:  "Deleted namespace",
+				FailureText:  "Failed to delete namespace",
+			})
+
+			ctx, cancel := OperationContext(types.TimeoutDeploy)
+			defer cancel()
+
+			resp, err := external.Kubectl.Delete(ctx, data, "", Args.DryRun)
+			done(err == nil)
+			if err != nil {
+				err = fmt.Errorf("Failed to delete namespace: %s\n%s", err, resp)
+			}
+
+			Audit(types.AuditEntry{
+				Action: types.AuditActionUninstall,
+				Kind:   "namespace",
+				Name:   ns.Name,
+			}, err)
+			if err != nil {
+				logger.Fatal(err)
+			}
+		}
+
+		if !Args.DryRun {
+			utils.WriteManifest(Args.Context)
+		} else {
+			logger.Info("Dry run mode, not writing manifest")
+		}
+
+		Audit(types.AuditEntry{
+			Action: types.AuditActionRemove,
+			Kind:   "namespace",
+			Name:   ns.Name,
+		}, nil)
+
+		logger.Infof("Removed %s namespace from the manifest", color.RedString(ns.Name))
+	},
+}
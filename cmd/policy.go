@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+)
+
+func PolicyPath() string {
+	return path.Join(Args.Context, types.PolicyFileName)
+}
+
+type loadedPolicy struct {
+	policy types.Policy
+	exists bool
+}
+
+var PolicyFuture = types.FutureFromFuncErr(func() (loadedPolicy, error) {
+	policy, exists, err := utils.ReadPolicy(PolicyPath())
+	if err != nil {
+		return loadedPolicy{}, err
+	}
+
+	if diags := policy.Validate(); len(diags) != 0 {
+		return loadedPolicy{}, fmt.Errorf("invalid policy %s, %s", PolicyPath(), diags[0].Message)
+	}
+
+	return loadedPolicy{policy: policy, exists: exists}, nil
+})
+
+// CheckPolicy runs the policy against the rendered resources of a release or single.
+// Violations of error rules block the deploy unless waived, expired waivers no longer count.
+func CheckPolicy(kind string, name string, waivers types.ManifestWaivers, rendered []byte) error {
+	loaded, err := PolicyFuture.Get()
+	if err != nil {
+		return err
+	}
+
+	resources, err := utils.ParseRenderedResources(rendered)
+	if err != nil {
+		return fmt.Errorf("failed to parse rendered manifests of %s %s, %v", kind, name, err)
+	}
+
+	now := time.Now()
+	blocking := 0
+	for _, violation := range utils.EvaluatePolicy(loaded.policy, resources) {
+		waiver, waived := waivers.Find(violation.Rule)
+		switch {
+		case waived && !waiver.Expired(now):
+			logger.Infof("%s %s %s (waived until %s, %s)", color.CyanString("waived"), kind, name, waiver.Expires, violation)
+		case violation.Severity == types.SeverityError:
+			if waived {
+				logger.Errorf("%s %s %s %s (waiver expired on %s)", violation.Severity.Colored(), kind, name, violation, waiver.Expires)
+			} else {
+				logger.Errorf("%s %s %s %s", violation.Severity.Colored(), kind, name, violation)
+			}
+
+			blocking++
+		default:
+			logger.Warnf("%s %s %s %s", violation.Severity.Colored(), kind, name, violation)
+		}
+	}
+
+	if blocking != 0 {
+		return fmt.Errorf("%d policy violations block the deploy of %s %s, fix them or add a waiver to the manifest", blocking, kind, name)
+	}
+
+	return nil
+}
+
+// checkReleasePolicy renders the release with helm template and checks the result, it does nothing when the context has no policy.
+func checkReleasePolicy(release types.ManifestRelease, chart types.HelmChart, values []byte) error {
+	loaded, err := PolicyFuture.Get()
+	if err != nil || !loaded.exists {
+		return err
+	}
+
+	done := utils.Loader(utils.LoaderOptions{
+		FetchingText: fmt.Sprintf("Rendering release %s", release.Name),
+		SuccessText:  fmt.Sprintf("Rendered release %s", release.Name),
+		FailureText:  fmt.Sprintf("Failed to render release %s", release.Name),
+	})
+
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	rendered, err := external.Helm.Template(ctx, release, chart, values)
+	done(err == nil)
+	if err != nil {
+		return fmt.Errorf("failed to render release for policy checks: %v\n%s", err, rendered)
+	}
+
+	return CheckPolicy("release", release.Name, release.Waivers, rendered)
+}
+
+// checkSinglePolicy checks the env substituted contents of a single, it does nothing when the context has no policy.
+func checkSinglePolicy(single types.ManifestSingle, values []byte) error {
+	loaded, err := PolicyFuture.Get()
+	if err != nil || !loaded.exists {
+		return err
+	}
+
+	return CheckPolicy("single", single.Name, single.Waivers, values)
+}
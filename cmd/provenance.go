@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+)
+
+func ArchiveDigest(data []byte) string {
+	return "sha256:" + hex.EncodeToString(utils.Sum256(data))
+}
+
+var fingerprintRegex = regexp.MustCompile(`Using Key With Fingerprint:\s*([0-9A-Fa-f]+)`)
+
+// pullChartArchive downloads a chart into a temporary directory and returns the path of the archive, cleanup removes the directory.
+func pullChartArchive(chart types.HelmChart, keyring string) (string, []byte, func(), error) {
+	dir, err := os.MkdirTemp("", "helm-manager-chart-")
+	if err != nil {
+		return "", nil, func() {}, err
+	}
+
+	cleanup := func() {
+		os.RemoveAll(dir)
+	}
+
+	ctx, cancel := OperationContext(types.TimeoutRepo)
+	defer cancel()
+
+	resp, err := external.Helm.PullChart(ctx, chart, dir, keyring)
+	if err != nil {
+		cleanup()
+		return "", resp, func() {}, err
+	}
+
+	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	if err != nil || len(archives) != 1 {
+		cleanup()
+		return "", resp, func() {}, fmt.Errorf("expected helm pull to download one chart archive, found %d", len(archives))
+	}
+
+	return archives[0], resp, cleanup, nil
+}
+
+// LockChart pulls the chart of a release, checks it against the digest and signer of the old lock and records them in the new lock.
+// The returned chart points at the downloaded archive so exactly what was verified gets deployed, cleanup removes the archive.
+// Vendored charts are checked in place, local charts are not archives and are returned unchanged.
+func LockChart(result *UpgradeResult, chart types.HelmChart) (types.HelmChart, func(), error) {
+	if chart.IsLocal && !isVendored(chart) {
+		return chart, func() {}, nil
+	}
+
+	keyring := ""
+	if Manifest.Keyring != "" {
+		keyring = utils.MergeRelativePath(Args.Context, Manifest.Keyring)
+	}
+
+	done := utils.Loader(utils.LoaderOptions{
+		FetchingText: fmt.Sprintf("Verifying chart %s %s", chart.RepoName, chart.Version),
+		SuccessText:  fmt.Sprintf("Verified chart %s %s", chart.RepoName, chart.Version),
+		FailureText:  fmt.Sprintf("Failed to verify chart %s %s", chart.RepoName, chart.Version),
+	})
+
+	var (
+		archive string
+		resp    []byte
+		cleanup = func() {}
+		err     error
+	)
+
+	if isVendored(chart) {
+		archive = chart.LocalPath
+		if keyring != "" {
+			ctx, cancel := OperationContext(types.TimeoutQuery)
+			defer cancel()
+
+			if resp, err = external.Helm.VerifyChart(ctx, archive, keyring); err != nil {
+				done(false)
+				return chart, cleanup, fmt.Errorf("failed to verify vendored chart %s: %v\n%s", archive, err, resp)
+			}
+		}
+	} else if archive, resp, cleanup, err = pullChartArchive(chart, keyring); err != nil {
+		done(false)
+		return chart, cleanup, fmt.Errorf("failed to pull chart %s %s: %v\n%s", chart.RepoName, chart.Version, err, resp)
+	}
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		done(false)
+		cleanup()
+		return chart, func() {}, fmt.Errorf("failed to read chart archive: %v", err)
+	}
+
+	lock := &result.NewLock
+	digest := ArchiveDigest(data)
+	signer := ""
+	if keyring != "" {
+		if match := fingerprintRegex.FindSubmatch(resp); match != nil {
+			signer = string(match[1])
+		}
+	}
+
+	sameChart := result.OldLock.Chart == lock.Chart && result.OldLock.Version == lock.Version
+	if sameChart && result.OldLock.Digest != "" && result.OldLock.Digest != digest {
+		if !Args.DeployCmd.AcceptNewDigest {
+			done(false)
+			cleanup()
+			return chart, func() {}, fmt.Errorf("the archive of %s %s changed since it was locked\n   locked:  %s\n   current: %s\nthe repo republished this version, if this is expected run with %s", lock.Chart, lock.Version, result.OldLock.Digest, digest, color.YellowString("--accept-new-digest"))
+		}
+
+		logger.Warnf("accepting new digest %s for %s %s", digest, lock.Chart, lock.Version)
+	}
+
+	if sameChart && result.OldLock.Signer != "" && result.OldLock.Signer != signer {
+		if !Args.DeployCmd.AcceptNewDigest {
+			done(false)
+			cleanup()
+			return chart, func() {}, fmt.Errorf("%s %s was signed by %s when it was locked, but is now signed by %s, if this is expected run with %s", lock.Chart, lock.Version, result.OldLock.Signer, utils.OrStr(signer, "nobody"), color.YellowString("--accept-new-digest"))
+		}
+
+		logger.Warnf("accepting new signer %s for %s %s", utils.OrStr(signer, "nobody"), lock.Chart, lock.Version)
+	}
+
+	done(true)
+
+	lock.Digest = digest
+	lock.Signer = signer
+
+	document, err := utils.ParseYaml(result.Document)
+	if err != nil {
+		cleanup()
+		return chart, func() {}, err
+	}
+
+	if document.Content[0], err = lockNode(*lock); err != nil {
+		cleanup()
+		return chart, func() {}, err
+	}
+
+	if result.Document, err = utils.MarshalYaml(document); err != nil {
+		cleanup()
+		return chart, func() {}, err
+	}
+
+	chart.IsLocal = true
+	chart.LocalPath = archive
+
+	return chart, cleanup, nil
+}
@@ -26,14 +26,16 @@ func init() {
 
 	{
 		removeCmd.AddCommand(removeSingleCmd)
-		removeSingleCmd.Flags().StringVar(&Args.Name, "name", "", "Name of the single")
+		removeSingleCmd.Flags().StringSliceVar(&Args.Names, "name", nil, "Name of the single, can be repeated")
+		removeSingleCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Remove the singles matching the selector, like tier=backend,team!=data")
 		removeSingleCmd.Flags().BoolVar(&Args.Delete, "delete", false, "Delete the single file from the system")
 		removeSingleCmd.Flags().BoolVar(&Args.Deploy, "deploy", false, "Apply deletion of the single to the cluster")
 	}
 
 	{
 		removeCmd.AddCommand(removeReleaseCmd)
-		removeReleaseCmd.Flags().StringVar(&Args.Name, "name", "", "Name of the release")
+		removeReleaseCmd.Flags().StringSliceVar(&Args.Names, "name", nil, "Name of the release, can be repeated")
+		removeReleaseCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Remove the releases matching the selector, like tier=backend,team!=data")
 		removeReleaseCmd.Flags().BoolVar(&Args.Delete, "delete", false, "Delete the release file from the system")
 		removeReleaseCmd.Flags().BoolVar(&Args.Deploy, "deploy", false, "Apply deletion of the release to the cluster")
 	}
@@ -71,25 +73,12 @@ var removeCmd = &cobra.Command{
 }
 
 var removeReleaseCmd = &cobra.Command{
-	Use:   "release",
-	Short: "Remove a release from the manifest",
-	Long:  "Remove a release from the manifest",
+	Use:     "release",
+	Short:   "Remove releases from the manifest",
+	Long:    "Remove releases from the manifest",
+	Example: "   helm-manager remove release [NAME...]\n   helm-manager remove release mongo redis --delete\n   helm-manager remove release -l tier=backend",
 	Args: ui.PositionalArgs([]ui.RequiredArg{
-		ui.Arg[string]{
-			Name:       "name",
-			Ptr:        &Args.Name,
-			Positional: true,
-			Validator:  types.EqualValidator(types.ToStringer(`"%s" is not a release name in the manifest`), types.FutureFromStringers(types.FutureFromPtr(&Manifest.Releases))),
-			UI: ui.PromptUiSelectorFunc[string]("Release", "Which release do you want to remove?", func(i int) error {
-				Args.Name = Manifest.Releases[i].Name
-				return nil
-			}, types.FutureInterfacerArray[types.ManifestRelease, types.Selectable](types.FutureFromPtr(&Manifest.Releases))),
-			Callback: func(s string) error {
-				Args.Name = strings.ToLower(s)
-
-				return nil
-			},
-		},
+		releaseNamesArg("Which releases do you want to remove?", selectorSet),
 		ui.Arg[bool]{
 			Name:       "delete",
 			Ptr:        &Args.Delete,
@@ -108,7 +97,7 @@ var removeReleaseCmd = &cobra.Command{
 			Disabled: types.FutureFromFunc(func() bool {
 				return (!Args.Delete && !Args.Deploy) || Args.DryRun
 			}),
-			UI: ui.PromptUiConfirmFunc("Are you sure you want to remove this release", false),
+			UI: ui.PromptUiConfirmFunc("Are you sure you want to remove the selected releases", false),
 			Callback: func(b bool) error {
 				if !b {
 					return fmt.Errorf("Aborted")
@@ -126,66 +115,103 @@ var removeReleaseCmd = &cobra.Command{
 		}
 	}),
 	Run: func(cmd *cobra.Command, args []string) {
-		var (
-			release types.ManifestRelease
-			i       int
-		)
-		for i, release = range Manifest.Releases {
-			if strings.ToLower(string(release.Name)) == Args.Name {
-				Manifest.Releases = append(Manifest.Releases[:i], Manifest.Releases[i+1:]...)
-				break
-			}
+		if Args.DryRun {
+			logger.Info("Dry run mode, not writing manifest")
 		}
 
-		if Args.Delete {
-			if err := os.Remove(ReleasePath(release.Name)); err != nil {
-				logger.Fatal("failed to delete release file", zap.Error(err))
-			}
+		for _, release := range selectedReleases() {
+			removeRelease(release)
 		}
 
-		if Args.Deploy {
-			if Args.DryRun {
-				logger.Info("Running in dry run mode, not actually deleting the release")
-			}
+		GarbageCollectVendor()
+	},
+}
 
-			done := utils.Loader(utils.LoaderOptions{
-				FetchingText: "Deleting release",
-				SuccessText:  "Deleted release",
-				FailureText:  "Failed to delete release",
-			})
+// removeRelease uninstalls a release and deletes its file when asked to, then takes it out of the manifest.
+// The manifest is written after every release, so a failure further down does not forget the releases already uninstalled.
+func removeRelease(release types.ManifestRelease) {
+	if Args.Deploy {
+		uninstallRelease(release)
+	}
 
-			resp, err := external.Helm.UninstallRelease(release, Args.DryRun)
-			done(err == nil)
-			if err != nil {
-				logger.Fatalf("Failed to delete release: %s\n%s", err, resp)
-			}
+	if Args.Delete {
+		if err := os.Remove(ReleasePath(release.Name)); err != nil {
+			logger.Fatal("failed to delete release file", zap.Error(err))
 		}
+	}
 
-		if !Args.DryRun {
-			utils.WriteManifest(Args.Context)
-		} else {
-			logger.Info("Dry run mode, not writing manifest")
+	if _, i := Manifest.ReleaseIdxByName(release.Name); i != -1 {
+		Manifest.Releases = append(Manifest.Releases[:i], Manifest.Releases[i+1:]...)
+	}
+
+	if !Args.DryRun {
+		utils.WriteManifest(Args.Context)
+	}
+
+	Audit(types.AuditEntry{
+		Action:     types.AuditActionRemove,
+		Kind:       "release",
+		Name:       release.Name,
+		Namespace:  release.Namespace,
+		OldVersion: release.Chart.Version,
+	}, nil)
+
+	logger.Infof("Removed %s release from the manifest", color.RedString(string(release.Name)))
+}
+
+func uninstallRelease(release types.ManifestRelease) {
+	if Args.DryRun {
+		logger.Info("Running in dry run mode, not actually deleting the release")
+	}
+
+	if err := LockCluster(); err != nil {
+		logger.Fatal(err)
+	}
+
+	hctx := HookContext{
+		Kind:         "release",
+		Name:         release.Name,
+		Namespace:    release.Namespace,
+		ChartVersion: release.Chart.Version,
+	}
+
+	err := WithHooks(release.Hooks, types.HookPreRemove, types.HookPostRemove, hctx, func() error {
+		done := utils.Loader(utils.LoaderOptions{
+			FetchingText: fmt.Sprintf("Deleting release %s", release.Name),
+			SuccessText:  fmt.Sprintf("Deleted release %s", release.Name),
+			FailureText:  fmt.Sprintf("Failed to delete release %s", release.Name),
+		})
+
+		ctx, cancel := ReleaseContext(release)
+		defer cancel()
+
+		resp, err := external.Helm.UninstallRelease(ctx, release, Args.DryRun)
+		done(err == nil)
+		if err != nil {
+			return fmt.Errorf("Failed to delete release: %s\n%s", err, resp)
 		}
 
-		logger.Infof("Removed %s release from the manifest", color.RedString(string(release.Name)))
-	},
+		return nil
+	})
+	Audit(types.AuditEntry{
+		Action:     types.AuditActionUninstall,
+		Kind:       "release",
+		Name:       release.Name,
+		Namespace:  release.Namespace,
+		OldVersion: release.Chart.Version,
+	}, err)
+	if err != nil {
+		logger.Fatal(err)
+	}
 }
 
 var removeSingleCmd = &cobra.Command{
-	Use:   "single",
-	Short: "Remove a new single from the manifest",
-	Long:  "Remove a new single from the manifest",
+	Use:     "single",
+	Short:   "Remove singles from the manifest",
+	Long:    "Remove singles from the manifest",
+	Example: "   helm-manager remove single [NAME...]\n   helm-manager remove single ingresses app-config --delete",
 	Args: ui.PositionalArgs([]ui.RequiredArg{
-		ui.Arg[string]{
-			Name:       "name",
-			Ptr:        &Args.Name,
-			Positional: true,
-			Validator:  types.EqualValidator(types.ToStringer(`"%s" is not a single name in the manifest`), types.FutureFromStringers(types.FutureFromPtr(&Manifest.Singles))),
-			UI: ui.PromptUiSelectorFunc[string]("Single", "Which single do you want to remove?", func(i int) error {
-				Args.Name = string(Manifest.Singles[i].Name)
-				return nil
-			}, types.FutureInterfacerArray[types.ManifestSingle, types.Selectable](types.FutureFromPtr(&Manifest.Singles))),
-		},
+		singleNamesArg("Which singles do you want to remove?", selectorSet),
 		ui.Arg[bool]{
 			Name: "delete",
 			Ptr:  &Args.Delete,
@@ -202,7 +228,7 @@ var removeSingleCmd = &cobra.Command{
 			Disabled: types.FutureFromFunc(func() bool {
 				return (!Args.Delete && !Args.Deploy) || Args.DryRun
 			}),
-			UI: ui.PromptUiConfirmFunc("Are you sure you want to remove this single", false),
+			UI: ui.PromptUiConfirmFunc("Are you sure you want to remove the selected singles", false),
 			Callback: func(b bool) error {
 				if !b {
 					return fmt.Errorf("Aborted")
@@ -220,47 +246,88 @@ var removeSingleCmd = &cobra.Command{
 		}
 	}),
 	Run: func(cmd *cobra.Command, _ []string) {
-		singleName := strings.ToLower(Args.Name)
-
-		var (
-			single types.ManifestSingle
-			i      int
-		)
-		for i, single = range Manifest.Singles {
-			if strings.ToLower(string(single.Name)) == singleName {
-				Manifest.Singles = append(Manifest.Singles[:i], Manifest.Singles[i+1:]...)
-				break
-			}
+		if Args.DryRun {
+			logger.Info("Dry run mode, not writing manifest")
 		}
 
-		if Args.Delete {
-			done := utils.Loader(utils.LoaderOptions{
-				FetchingText: "Deleting single",
-				SuccessText:  "Deleted single",
-				FailureText:  "Failed to delete single",
-			})
-
-			values, err := os.ReadFile(SinglePath(single.Name))
-			if err != nil {
-				done(false)
-				logger.Fatal("failed to read single file", zap.Error(err))
-			}
+		for _, single := range selectedSingles() {
+			removeSingle(single)
+		}
+	},
+}
 
-			resp, err := external.Kubectl.Delete(values, Args.Namespace, Args.DryRun)
-			done(err == nil)
-			if err != nil {
-				logger.Fatalf("Failed to delete single: %s\n%s", err, resp)
-			}
+// removeSingle deletes the resources of a single from the cluster when asked to, then takes it out of the manifest.
+// The manifest is written after every single, so a failure further down does not forget the singles already deleted.
+func removeSingle(single types.ManifestSingle) {
+	if Args.Delete {
+		deleteSingle(single)
+	}
+
+	for i, s := range Manifest.Singles {
+		if strings.EqualFold(s.Name, single.Name) {
+			Manifest.Singles = append(Manifest.Singles[:i], Manifest.Singles[i+1:]...)
+			break
 		}
+	}
 
-		if !Args.DryRun {
-			utils.WriteManifest(Args.Context)
-		} else {
-			logger.Info("Dry run mode, not writing manifest")
+	if !Args.DryRun {
+		utils.WriteManifest(Args.Context)
+	}
+
+	Audit(types.AuditEntry{
+		Action:    types.AuditActionRemove,
+		Kind:      "single",
+		Name:      single.Name,
+		Namespace: single.Namespace,
+	}, nil)
+
+	logger.Infof("Removed %s single from the manifest", color.RedString(single.Name))
+}
+
+func deleteSingle(single types.ManifestSingle) {
+	values, err := os.ReadFile(SinglePath(single.Name))
+	if err != nil {
+		logger.Fatal("failed to read single file", zap.Error(err))
+	}
+
+	if err := LockCluster(); err != nil {
+		logger.Fatal(err)
+	}
+
+	hctx := HookContext{
+		Kind:      "single",
+		Name:      single.Name,
+		Namespace: single.Namespace,
+		Values:    values,
+	}
+
+	err = WithHooks(single.Hooks, types.HookPreRemove, types.HookPostRemove, hctx, func() error {
+		done := utils.Loader(utils.LoaderOptions{
+			FetchingText: fmt.Sprintf("Deleting single %s", single.Name),
+			SuccessText:  fmt.Sprintf("Deleted single %s", single.Name),
+			FailureText:  fmt.Sprintf("Failed to delete single %s", single.Name),
+		})
+
+		ctx, cancel := OperationContext(types.TimeoutDeploy)
+		defer cancel()
+
+		resp, err := external.Kubectl.Delete(ctx, values, Args.Namespace, Args.DryRun)
+		done(err == nil)
+		if err != nil {
+			return fmt.Errorf("Failed to delete single: %s\n%s", err, resp)
 		}
 
-		logger.Infof("Removed %s single from the manifest", color.RedString(string(singleName)))
-	},
+		return nil
+	})
+	Audit(types.AuditEntry{
+		Action:    types.AuditActionUninstall,
+		Kind:      "single",
+		Name:      single.Name,
+		Namespace: single.Namespace,
+	}, err)
+	if err != nil {
+		logger.Fatal(err)
+	}
 }
 
 var removeRepoCmd = &cobra.Command{
@@ -326,8 +393,12 @@ var removeRepoCmd = &cobra.Command{
 				} else {
 					for _, r := range helmRepos {
 						if strings.ToLower(r.Name) == repo {
-							if resp, err := external.Helm.RemoveRepo(r); err != nil {
-								logger.Error("failed to delete repo %v\n%s", err, resp)
+							ctx, cancel := OperationContext(types.TimeoutRepo)
+							resp, err := external.Helm.RemoveRepo(ctx, r)
+							cancel()
+
+							if err != nil {
+								logger.Errorf("failed to delete repo %v\n%s", err, resp)
 							}
 
 							break
@@ -345,6 +416,12 @@ var removeRepoCmd = &cobra.Command{
 			logger.Info("Dry run mode, not writing manifest")
 		}
 
+		Audit(types.AuditEntry{
+			Action: types.AuditActionRemove,
+			Kind:   "repo",
+			Name:   repo,
+		}, nil)
+
 		logger.Infof("Removed %s repo from the manifest", color.RedString(string(repo)))
 	},
 }
@@ -388,6 +465,12 @@ var removeEnvCmd = &cobra.Command{
 			logger.Info("Dry run mode, not writing manifest")
 		}
 
+		Audit(types.AuditEntry{
+			Action: types.AuditActionRemove,
+			Kind:   "env",
+			Name:   env,
+		}, nil)
+
 		logger.Infof("Removed %s to the env variable whitelist", color.RedString(string(env)))
 	},
 }
@@ -431,6 +514,12 @@ var removeLocalChartCmd = &cobra.Command{
 			logger.Info("Dry run mode, not writing manifest")
 		}
 
+		Audit(types.AuditEntry{
+			Action: types.AuditActionRemove,
+			Kind:   "local-chart",
+			Name:   chart,
+		}, nil)
+
 		logger.Infof("Removed %s from the local charts", color.RedString(string(chart)))
 	},
 }
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().StringVar(&Args.Name, "name", "", "Name of the release")
+	rollbackCmd.Flags().StringVar(&Args.RollbackCmd.Revision, "revision", "", "Helm revision to roll back to, defaults to the previous revision")
+}
+
+var ReleaseHistoryFuture = types.FutureFromFuncErr(func() ([]types.HelmReleaseRevision, error) {
+	release := Manifest.ReleaseByName(Args.Name)
+
+	done := utils.Loader(utils.LoaderOptions{
+		FetchingText: fmt.Sprintf("Fetching history of release %s", release.Name),
+		SuccessText:  fmt.Sprintf("Fetched history of release %s", release.Name),
+		FailureText:  fmt.Sprintf("Failed to fetch history of release %s", release.Name),
+	})
+	ctx, cancel := OperationContext(types.TimeoutQuery)
+	defer cancel()
+
+	revisions, err := external.Helm.History(ctx, release)
+	done(err == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// newest first, the current revision is not a rollback target
+	ret := make([]types.HelmReleaseRevision, 0, len(revisions))
+	for i := len(revisions) - 2; i >= 0; i-- {
+		ret = append(ret, revisions[i])
+	}
+
+	return ret, nil
+})
+
+var rollbackCmd = &cobra.Command{
+	Use:     "rollback",
+	Short:   "Roll a release back to an older helm revision",
+	Long:    "Roll a release back to an older helm revision, the manifest is not changed",
+	Example: "   helm-manager rollback [NAME] [REVISION]\n   helm-manager rollback mongo 3" + USAGE_EXTRA,
+	Args: ui.PositionalArgs([]ui.RequiredArg{
+		ui.Arg[string]{
+			Name:       "name",
+			Ptr:        &Args.Name,
+			Positional: true,
+			Validator:  types.EqualValidator(types.ToStringer(`"%s" is not a release in the manifest`), types.FutureFromStringers(types.FutureFromPtr(&Manifest.Releases))),
+			UI: ui.PromptUiSelectorFunc[string]("Release", "Which release do you want to roll back", func(i int) error {
+				Args.Name = Manifest.Releases[i].Name
+				return nil
+			}, types.FutureInterfacerArray[types.ManifestRelease, types.Selectable](types.FutureFromPtr(&Manifest.Releases))),
+		},
+		ui.Arg[string]{
+			Name:       "revision",
+			Ptr:        &Args.RollbackCmd.Revision,
+			Positional: true,
+			DependsOn:  []string{"name"},
+			Validator: types.MultiValidator[string](
+				types.OptionalEmptyValidator[string](),
+				types.ValidatorFunction[string](func(s string) error {
+					if i, err := strconv.Atoi(s); err != nil || i <= 0 {
+						return fmt.Errorf("revision must be a positive number")
+					}
+
+					return nil
+				}),
+			),
+			UI: ui.PromptUiSelectorFunc[string]("Revision", "Which revision do you want to roll back to", func(i int) error {
+				revisions, err := ReleaseHistoryFuture.Get()
+				if err != nil {
+					return err
+				}
+
+				Args.RollbackCmd.Revision = strconv.Itoa(revisions[i].Revision)
+				return nil
+			}, types.FutureInterfacerArray[types.HelmReleaseRevision, types.Selectable](ReleaseHistoryFuture)),
+			Completions: types.Map(ReleaseHistoryFuture, func(revisions []types.HelmReleaseRevision) ([]string, error) {
+				ret := make([]string, len(revisions))
+				for i, revision := range revisions {
+					ret[i] = strconv.Itoa(revision.Revision)
+				}
+
+				return ret, nil
+			}),
+		},
+		ui.Arg[bool]{
+			Name: "confirm",
+			Ptr:  &Args.Confirm,
+			Disabled: types.FutureFromFunc(func() bool {
+				return Args.DryRun
+			}),
+			UI: ui.PromptUiConfirmFunc("Are you sure you want to roll back this release", false),
+			Callback: func(b bool) error {
+				if !b {
+					return fmt.Errorf("Aborted")
+				}
+
+				return nil
+			},
+		},
+	}, func(cmd *cobra.Command) {
+		zap.S().Infof("* %s *", color.YellowString("Helm Manager Rollback"))
+		ManifestExist(cmd)
+
+		if len(Manifest.Releases) == 0 {
+			logger.Fatal("no releases added to the manifest")
+		}
+	}),
+	Run: func(cmd *cobra.Command, _ []string) {
+		release := Manifest.ReleaseByName(Args.Name)
+
+		revisions, err := ReleaseHistoryFuture.Get()
+		if err != nil {
+			logger.Fatalf("failed to get release history: %s", err)
+		}
+
+		if len(revisions) == 0 {
+			logger.Fatalf("release %s has no previous revisions", release.Name)
+		}
+
+		target := revisions[0]
+		if Args.RollbackCmd.Revision != "" {
+			revision, _ := strconv.Atoi(Args.RollbackCmd.Revision)
+
+			target = types.HelmReleaseRevision{}
+			for _, r := range revisions {
+				if r.Revision == revision {
+					target = r
+					break
+				}
+			}
+
+			if target.Revision == 0 {
+				logger.Fatalf("revision %s is not a previous revision of %s", Args.RollbackCmd.Revision, release.Name)
+			}
+		}
+
+		deployed, err := findDeployedRelease(release)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		if Args.DryRun {
+			logger.Info("Dry run, not actually rolling back release")
+		}
+
+		if err := LockCluster(); err != nil {
+			logger.Fatal(err)
+		}
+
+		done := utils.Loader(utils.LoaderOptions{
+			FetchingText: fmt.Sprintf("Rolling back release %s to revision %d", release.Name, target.Revision),
+			SuccessText:  fmt.Sprintf("Rolled back release %s to revision %d", release.Name, target.Revision),
+			FailureText:  fmt.Sprintf("Failed to roll back release %s to revision %d", release.Name, target.Revision),
+		})
+
+		ctx, cancel := ReleaseContext(release)
+		defer cancel()
+
+		resp, err := external.Helm.Rollback(ctx, release, target.Revision, Args.DryRun)
+		done(err == nil)
+		if err != nil {
+			err = fmt.Errorf("failed to execute helm rollback command: %v\n%s", err, resp)
+		}
+
+		Audit(types.AuditEntry{
+			Action:     types.AuditActionRollback,
+			Kind:       "release",
+			Name:       release.Name,
+			Namespace:  release.Namespace,
+			OldVersion: deployed.Version(),
+			NewVersion: target.Version(),
+		}, err)
+
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		if target.Version() != release.Chart.Version {
+			logger.Warnf("The manifest still pins %s to %s, the next deploy will move it back to that version", release.Name, release.Chart.Version)
+		}
+	},
+}
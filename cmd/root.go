@@ -7,6 +7,7 @@ import (
 	"github.com/seventv/helm-manager/v2/cmd/args"
 	"github.com/seventv/helm-manager/v2/cmd/ui"
 	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/redact"
 	"github.com/seventv/helm-manager/v2/types"
 	"github.com/seventv/helm-manager/v2/utils"
 	"github.com/spf13/cobra"
@@ -16,11 +17,18 @@ import (
 var Args = args.Args
 var Manifest = types.GlobalManifest
 
+// ManifestErr is set when the manifest could not be read by an offline command.
+var ManifestErr error
+
 const USAGE_EXTRA = "\nAll arguments are optional, if not provided, you will be prompted to enter them.\nAll arguments can be passed as kwargs."
 
 func init() {
-	rootCmd.PersistentFlags().BoolVar(&Args.Debug, "debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().BoolVar(&Args.Debug, "debug", false, "Enable debug mode, the same as -vv")
+	rootCmd.PersistentFlags().CountVarP(&Args.Verbose, "verbose", "v", "Log more, -v for debug messages and external commands with their output, -vv for the time and caller as well")
+	rootCmd.PersistentFlags().StringVar(&Args.LogFile, "log-file", "", "Append json logs of everything, including every external command, to this file")
+	rootCmd.PersistentFlags().BoolVar(&Args.ShowSecrets, "show-secrets", false, "Do not mask secrets, such as the values of env variables, in the output and logs")
 	rootCmd.PersistentFlags().BoolVar(&Args.NonInteractive, "term", false, "Disable interactive mode")
+	rootCmd.PersistentFlags().StringVar(&Args.Answers, "answers", "", "Yaml file answering the prompts, a map from argument names like name or namespace to their values")
 	rootCmd.PersistentFlags().BoolVar(&Args.DryRun, "dry-run", false, "Dry run mode ( your actions wont be saved or applied )")
 	rootCmd.PersistentFlags().BoolVar(&Args.Confirm, "confirm", false, "Confirm your actions")
 	rootCmd.PersistentFlags().StringVar(&Args.EnvFile, "env", ".env", "Environment file to use")
@@ -29,25 +37,66 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&Args.Context, "context", "c", wd, "Context to use, working directory")
 
 	cobra.OnInitialize(func() {
+		// completion reads the context itself once the flags of the completed command are parsed, see prepareCompletion
+		if completing() {
+			return
+		}
+
+		redact.SetEnabled(!Args.ShowSecrets)
+
+		verbosity := Args.Verbose
+		if Args.Debug && verbosity < logger.VerbosityVerbose {
+			verbosity = logger.VerbosityVerbose
+		}
+
+		if err := logger.Setup(logger.Options{Verbosity: verbosity, File: Args.LogFile}); err != nil {
+			logger.Fatal(err)
+		}
+
 		Args.Context = utils.MergeRelativePath(wd, Args.Context)
 
+		offline := IsOffline(currentCommand()) || isCompletionScript(currentCommand())
+
 		err := utils.ReadManifest(Args.Context)
 		if err != nil {
-			logger.Fatal(err)
-		}
+			if !offline {
+				logger.Fatal(err)
+			}
 
-		if Manifest.Exists {
-			EnvMapFuture.GetOrPanic()
+			ManifestErr = err
 		}
 
-		if Args.Debug {
-			logger.SetDebug(true)
+		if Manifest.Exists && !offline {
+			if Manifest.SourceAPIVersion != types.ManifestAPIVersion {
+				logger.Warnf("manifest uses apiVersion %s, run `%s migrate` to upgrade it to %s", Manifest.SourceAPIVersion, rootCmd.Name(), types.ManifestAPIVersion)
+			}
+
+			EnvMapFuture.GetOrPanic()
 		}
 	})
 }
 
+func currentCommand() *cobra.Command {
+	cmd, _, err := rootCmd.Find(os.Args[1:])
+	if err != nil {
+		return rootCmd
+	}
+
+	return cmd
+}
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	handleSignals()
+	logger.OnExit(printStoppedCommands)
+
+	if completing() {
+		ui.RegisterCompletions(rootCmd, prepareCompletion)
+	}
+
+	err := rootCmd.Execute()
+	logger.RunExitHooks()
+
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -57,15 +106,12 @@ var rootCmd = &cobra.Command{
 	Short: "Helm-Manager is a tool to manage helm charts and k8s manifests",
 	Long:  `A tool to manage helm charts and k8s manifests, allowing you to easily install, upgrade, and delete charts and manifests.`,
 	Args:  ui.SubCommandRequired(cobra.NoArgs),
-	CompletionOptions: cobra.CompletionOptions{
-		HiddenDefaultCmd: true,
-	},
 	Run: func(cmd *cobra.Command, _ []string) {
 		zap.S().Infof("* %s *\r", color.CyanString("Helm Manager"))
 
 		cmds := make([]ui.SelectableCommand, 0, len(cmd.Commands()))
 		for _, cmd := range cmd.Commands() {
-			if !cmd.Hidden && cmd.Name() != "help" {
+			if !cmd.Hidden && cmd.Name() != "help" && cmd.Name() != "completion" {
 				cmds = append(cmds, ui.CmdSelectable(cmd))
 			}
 		}
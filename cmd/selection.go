@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seventv/helm-manager/v2/cmd/ui"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+)
+
+// selectorSet disables the name arguments, --selector picks the entries instead.
+var selectorSet = types.FutureFromFunc(func() bool {
+	return Args.Selector != ""
+})
+
+func parseSelectorArg() types.Selector {
+	selector, err := types.ParseSelector(Args.Selector)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	return selector
+}
+
+// namesCallback lowercases the picked names, Args.Name is set as well when there is only one so the arguments after it can use it.
+func namesCallback(names []string) error {
+	for i, name := range names {
+		names[i] = strings.ToLower(name)
+	}
+
+	if len(names) == 1 {
+		Args.Name = names[0]
+	}
+
+	return nil
+}
+
+// multiSelectorFunc asks for any number of items and stores the names of the picked ones in Args.Names.
+func multiSelectorFunc[T types.Selectable](label string, longLabel string, items *[]T) func() ([]string, error) {
+	return ui.PromptUiMultiSelectorFunc(label, longLabel, func(idxs []int) error {
+		if len(idxs) == 0 {
+			return fmt.Errorf("nothing selected")
+		}
+
+		Args.Names = make([]string, len(idxs))
+		for i, idx := range idxs {
+			Args.Names[i] = (*items)[idx].Selected()
+		}
+
+		return nil
+	}, types.FutureInterfacerArray[T, types.Selectable](types.FutureFromPtr(items)))
+}
+
+func releaseNamesArg(longLabel string, disabled types.Future[bool]) ui.ListArg {
+	return ui.ListArg{
+		Name:       "name",
+		Ptr:        &Args.Names,
+		Positional: true,
+		Disabled:   disabled,
+		Validator:  types.EqualValidator(types.ToStringer(`"%s" is not a release in the manifest`), types.FutureFromStringers(types.FutureFromPtr(&Manifest.Releases))),
+		UI:         multiSelectorFunc("Releases", longLabel, &Manifest.Releases),
+		Callback:   namesCallback,
+	}
+}
+
+func singleNamesArg(longLabel string, disabled types.Future[bool]) ui.ListArg {
+	return ui.ListArg{
+		Name:       "name",
+		Ptr:        &Args.Names,
+		Positional: true,
+		Disabled:   disabled,
+		Validator:  types.EqualValidator(types.ToStringer(`"%s" is not a single in the manifest`), types.FutureFromStringers(types.FutureFromPtr(&Manifest.Singles))),
+		UI:         multiSelectorFunc("Singles", longLabel, &Manifest.Singles),
+		Callback:   namesCallback,
+	}
+}
+
+// selectedReleases are the releases picked by name or matched by --selector, in manifest order for a selector.
+func selectedReleases() []types.ManifestRelease {
+	if Args.Selector != "" {
+		releases := filteredReleases()
+		if len(releases) == 0 {
+			logger.Fatalf("no releases match the selector %s", Args.Selector)
+		}
+
+		return releases
+	}
+
+	releases := make([]types.ManifestRelease, len(Args.Names))
+	for i, name := range Args.Names {
+		releases[i] = Manifest.ReleaseByName(name)
+	}
+
+	return releases
+}
+
+// selectedSingles are the singles picked by name or matched by --selector, in manifest order for a selector.
+func selectedSingles() []types.ManifestSingle {
+	if Args.Selector != "" {
+		singles := filteredSingles()
+		if len(singles) == 0 {
+			logger.Fatalf("no singles match the selector %s", Args.Selector)
+		}
+
+		return singles
+	}
+
+	singles := make([]types.ManifestSingle, len(Args.Names))
+	for i, name := range Args.Names {
+		singles[i] = Manifest.SingleByName(name)
+	}
+
+	return singles
+}
+
+// filteredReleases are all releases, or the ones matching --selector when it is set.
+func filteredReleases() []types.ManifestRelease {
+	if Args.Selector == "" {
+		return Manifest.Releases
+	}
+
+	return types.SelectMatching(parseSelectorArg(), Manifest.Releases)
+}
+
+// filteredSingles are all singles, or the ones matching --selector when it is set.
+func filteredSingles() []types.ManifestSingle {
+	if Args.Selector == "" {
+		return Manifest.Singles
+	}
+
+	return types.SelectMatching(parseSelectorArg(), Manifest.Singles)
+}
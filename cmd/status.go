@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/redact"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVarP(&Args.Output, "output", "o", "text", "Output format, text or json")
+	statusCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Only compare the releases matching the selector, namespaces are skipped, like tier=backend,team!=data")
+}
+
+func releaseStatus(release types.ManifestRelease) (types.ReleaseStatus, error) {
+	status := types.ReleaseStatus{
+		Name:      release.Name,
+		Namespace: release.Namespace,
+		Chart:     release.Chart.RepoName(),
+		Version:   release.Chart.Version,
+	}
+
+	deployed, err := findDeployedRelease(release)
+	if err != nil {
+		return status, err
+	}
+
+	if deployed.Name == "" {
+		status.Status = "not deployed"
+		status.Drift = append(status.Drift, "release is not installed")
+		return status, nil
+	}
+
+	status.Status = deployed.Status
+	status.DeployedVersion = deployed.Version()
+
+	if deployed.Version() != release.Chart.Version {
+		status.Drift = append(status.Drift, fmt.Sprintf("chart version is %s, expected %s", deployed.Version(), release.Chart.Version))
+	}
+
+	if deployed.Status != "deployed" {
+		status.Drift = append(status.Drift, fmt.Sprintf("helm reports the release as %s", deployed.Status))
+	}
+
+	return status, nil
+}
+
+func namespaceStatus(ns types.ManifestNamespace) types.NamespaceStatus {
+	live, err := LiveNamespace(ns.Name)
+	if err != nil {
+		logger.Fatalf("failed to get namespace %s: %s", ns.Name, err)
+	}
+
+	return types.NamespaceStatus{
+		Name:    ns.Name,
+		Managed: ns.Managed,
+		Drift:   utils.NamespaceDrift(ns, live),
+	}
+}
+
+func statusState(drift []string) string {
+	if len(drift) == 0 {
+		return color.GreenString("in sync")
+	}
+
+	return color.YellowString("drifted")
+}
+
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Compare the releases and namespaces in the manifest to the cluster",
+	Long:    "Compare the releases and namespaces in the manifest to the cluster, reporting anything which drifted",
+	Example: "   helm-manager status\n   helm-manager status -o json\n   helm-manager status -l tier=backend",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if Args.Output != "text" && Args.Output != "json" {
+			logger.Fatalf("unknown output format \"%s\", must be text or json", Args.Output)
+		}
+
+		ManifestExist(cmd)
+
+		releases := filteredReleases()
+		status := types.Status{
+			Releases:   make([]types.ReleaseStatus, 0, len(releases)),
+			Namespaces: make([]types.NamespaceStatus, 0, len(Manifest.Namespaces)),
+		}
+
+		for _, release := range releases {
+			rs, err := releaseStatus(release)
+			if err != nil {
+				logger.Fatal(err)
+			}
+
+			status.Releases = append(status.Releases, rs)
+		}
+
+		// namespaces have no labels, a selector only picks releases
+		if Args.Selector == "" {
+			for _, ns := range Manifest.Namespaces {
+				status.Namespaces = append(status.Namespaces, namespaceStatus(ns))
+			}
+		}
+
+		if Args.Output == "json" {
+			enc := json.NewEncoder(redact.Writer(os.Stdout))
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(status); err != nil {
+				logger.Fatalf("failed to encode status: %s", err)
+			}
+
+			if status.Drifted() != 0 {
+				os.Exit(1)
+			}
+
+			return
+		}
+
+		zap.S().Infof("* %s *", color.BlueString("Helm Manager Status"))
+
+		for _, r := range status.Releases {
+			zap.S().Infof("release %s %s %s %s",
+				color.CyanString(fmt.Sprintf("%s/%s", r.Namespace, r.Name)),
+				r.Chart,
+				utils.OrStr(r.DeployedVersion, "-"),
+				statusState(r.Drift),
+			)
+
+			for _, drift := range r.Drift {
+				zap.S().Infof("    %s", color.YellowString(drift))
+			}
+		}
+
+		for _, ns := range status.Namespaces {
+			managed := "unmanaged"
+			if ns.Managed {
+				managed = "managed"
+			}
+
+			zap.S().Infof("namespace %s %s %s", color.CyanString(ns.Name), managed, statusState(ns.Drift))
+
+			for _, drift := range ns.Drift {
+				zap.S().Infof("    %s", color.YellowString(drift))
+			}
+		}
+
+		if drifted := status.Drifted(); drifted != 0 {
+			logger.Fatalf("%d releases or namespaces drifted from the manifest, run `helm-manager deploy all` to apply it", drifted)
+		}
+
+		logger.Info("everything is in sync")
+	},
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/seventv/helm-manager/v2/cmd/args"
+	"github.com/seventv/helm-manager/v2/types"
+	"gopkg.in/yaml.v3"
+)
+
+// answers are read from --answers, a yaml map from argument names to the values they would be prompted for.
+// Lists answer arguments taking several values, like the names of releases.
+var answers = types.FutureFromFuncErr(func() (map[string]any, error) {
+	ret := map[string]any{}
+	if args.Args.Answers == "" {
+		return ret, nil
+	}
+
+	data, err := os.ReadFile(args.Args.Answers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file %s, %v", args.Args.Answers, err)
+	}
+
+	if err := yaml.Unmarshal(data, &ret); err != nil {
+		return nil, fmt.Errorf("failed to parse answers file %s, %v", args.Args.Answers, err)
+	}
+
+	return ret, nil
+})
+
+// answer sets parg from the answers file, answered is false when the file has nothing for it.
+// An answer counts even when it is the zero value, so "confirm: false" is not asked again.
+func answer(parg rArgInterface) (answered bool, err error) {
+	values, _ := answers.Get()
+
+	value, ok := values[parg.Name()]
+	if !ok || value == nil {
+		return false, nil
+	}
+
+	items, isList := value.([]any)
+	if !isList {
+		items = []any{value}
+	} else if !parg.Repeated() {
+		return true, fmt.Errorf("the answer for %s must be a single value", parg.Name())
+	}
+
+	for _, item := range items {
+		if err := parg.Set(fmt.Sprint(item)); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
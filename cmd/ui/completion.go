@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/spf13/cobra"
+)
+
+// inspect is set while the arguments of a command are read for shell completion,
+// the closures of PositionalArgs and SubCommandRequired then hand them over instead of running.
+var inspect func(rargs []rArgInterface)
+
+// commandArgs returns the arguments cmd was given with PositionalArgs, nil for anything else.
+func commandArgs(cmd *cobra.Command) []rArgInterface {
+	if cmd.Args == nil {
+		return nil
+	}
+
+	var rargs []rArgInterface
+	inspect = func(r []rArgInterface) {
+		rargs = r
+	}
+	defer func() {
+		inspect = nil
+	}()
+
+	_ = cmd.Args(cmd, nil)
+
+	return rargs
+}
+
+func completions[T any](explicit types.Future[[]string], validator types.Validator[T]) []string {
+	var (
+		values []string
+		err    error
+	)
+
+	if explicit != nil {
+		values, err = explicit.Get()
+	} else if completer, ok := validator.(types.Completer); ok {
+		values, err = completer.Completions()
+	}
+
+	if err != nil {
+		return nil
+	}
+
+	return values
+}
+
+// filterCompletions keeps the values starting with toComplete, leaving out the ones already given.
+// Without any known values the shell falls back to completing files.
+func filterCompletions(values []string, toComplete string, given []string) ([]string, cobra.ShellCompDirective) {
+	if values == nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	seen := make(map[string]bool, len(given))
+	for _, v := range given {
+		seen[v] = true
+	}
+
+	ret := []string{}
+	for _, v := range values {
+		if !seen[v] && strings.HasPrefix(v, toComplete) {
+			seen[v] = true
+			ret = append(ret, v)
+		}
+	}
+
+	return ret, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePositional completes the next positional argument, after assigning the ones already given like PositionalArgs does.
+// A repeated argument which took the last value can take more, so its values are offered along with the next argument's.
+func completePositional(rargs []rArgInterface, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	rest, filled, err := setPositional(rargs, args, nil)
+	if err != nil || len(rest) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var values []string
+	if filled != nil && filled.Repeated() {
+		values = filled.Completions()
+	}
+
+	for _, parg := range rargs {
+		if parg.Positional() && parg.Empty() && !parg.Disabled() {
+			if next := parg.Completions(); next != nil {
+				values = append(append([]string{}, values...), next...)
+			}
+
+			break
+		}
+	}
+
+	return filterCompletions(values, toComplete, args)
+}
+
+// RegisterCompletions adds shell completion to cmd and all its sub commands, generated from the arguments given to PositionalArgs
+// so completion offers the same values the prompts and validators use. Flags named like an argument are completed as well.
+// prepare runs before completing, once the flags of the completed command are parsed.
+func RegisterCompletions(cmd *cobra.Command, prepare func()) {
+	if rargs := commandArgs(cmd); len(rargs) > 0 {
+		cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			prepare()
+
+			return completePositional(rargs, args, toComplete)
+		}
+
+		for _, rarg := range rargs {
+			rarg := rarg
+			if cmd.LocalFlags().Lookup(rarg.Name()) == nil {
+				continue
+			}
+
+			_ = cmd.RegisterFlagCompletionFunc(rarg.Name(), func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				prepare()
+
+				// the positional arguments can change what a flag accepts, like the versions of a chart
+				_, _, _ = setPositional(rargs, args, nil)
+				if rarg.Disabled() {
+					return nil, cobra.ShellCompDirectiveNoFileComp
+				}
+
+				return filterCompletions(rarg.Completions(), toComplete, nil)
+			})
+		}
+	}
+
+	for _, c := range cmd.Commands() {
+		RegisterCompletions(c, prepare)
+	}
+}
@@ -0,0 +1,634 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	cursorHome     = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;?]*[a-zA-Z]")
+
+// visibleLen is the width of s on the terminal, not counting color codes.
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(s, ""))
+}
+
+// truncateVisible cuts s to width visible characters, keeping its color codes.
+func truncateVisible(s string, width int) string {
+	if visibleLen(s) <= width {
+		return s
+	}
+
+	out := strings.Builder{}
+	visible := 0
+	for len(s) > 0 && visible < width {
+		if loc := ansiEscape.FindStringIndex(s); loc != nil && loc[0] == 0 {
+			out.WriteString(s[:loc[1]])
+			s = s[loc[1]:]
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s)
+		out.WriteRune(r)
+		s = s[size:]
+		visible++
+	}
+
+	return out.String() + "\x1b[0m"
+}
+
+func padVisible(s string, width int) string {
+	if n := visibleLen(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+
+	return s
+}
+
+// DashboardRow is one line of the dashboard, the Columns are shown after the label of the item.
+type DashboardRow struct {
+	Item    types.Selectable
+	Columns []string
+}
+
+// DashboardAction runs on the selected row when its key is pressed, anything written to log ends up in the log pane.
+type DashboardAction struct {
+	Key  rune
+	Name string
+	// Confirm is asked before the action runs, %s is replaced with the selected item.
+	Confirm string
+	// Suspend leaves the full screen while the action runs, for commands which take over the terminal such as an editor.
+	Suspend bool
+	Run     func(item types.Selectable, log io.Writer) error
+}
+
+// LogPane keeps the last lines written to it, lines ending in \r replace each other like they do on a terminal.
+type LogPane struct {
+	mtx     sync.Mutex
+	lines   []string
+	partial []byte
+	rewrite bool
+	scroll  int
+}
+
+const logPaneLines = 1000
+
+func (l *LogPane) Write(p []byte) (int, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	data := append(l.partial, p...)
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx == -1 {
+			break
+		}
+
+		line := string(data[:idx])
+		data = data[idx+1:]
+
+		rewrite := strings.HasSuffix(line, "\r")
+		line = strings.TrimRight(line, "\r")
+
+		if l.rewrite && len(l.lines) != 0 {
+			l.lines[len(l.lines)-1] = line
+		} else {
+			l.lines = append(l.lines, line)
+		}
+		l.rewrite = rewrite
+	}
+
+	l.partial = append([]byte(nil), data...)
+	if len(l.lines) > logPaneLines {
+		l.lines = append([]string(nil), l.lines[len(l.lines)-logPaneLines:]...)
+	}
+
+	return len(p), nil
+}
+
+// Tail returns the last n lines, moved up by the scroll position.
+func (l *LogPane) Tail(n int) []string {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.scroll > len(l.lines)-n {
+		l.scroll = len(l.lines) - n
+	}
+	if l.scroll < 0 {
+		l.scroll = 0
+	}
+
+	end := len(l.lines) - l.scroll
+	start := end - n
+	if start < 0 {
+		start = 0
+	}
+
+	return append([]string(nil), l.lines[start:end]...)
+}
+
+func (l *LogPane) Scroll(by int) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.scroll += by
+	if l.scroll < 0 {
+		l.scroll = 0
+	}
+}
+
+// Dashboard is a full screen list of items with actions bound to keys, a details pane for the selected item and a log pane.
+type Dashboard struct {
+	Title   string
+	Headers []string
+	// Rows loads the rows, it runs in the background when the dashboard starts and after every action.
+	Rows    func() ([]DashboardRow, error)
+	Actions []DashboardAction
+
+	Log LogPane
+
+	mtx       sync.Mutex
+	rows      []DashboardRow
+	loading   bool
+	running   string
+	selected  int
+	offset    int
+	filter    string
+	filtering bool
+	confirm   *DashboardAction
+	status    string
+	redraw    chan struct{}
+}
+
+func (d *Dashboard) setStatus(format string, args ...any) {
+	d.status = fmt.Sprintf(format, args...)
+}
+
+func (d *Dashboard) requestRedraw() {
+	select {
+	case d.redraw <- struct{}{}:
+	default:
+	}
+}
+
+// refresh loads the rows in the background, the dashboard keeps drawing the old rows until they are loaded.
+func (d *Dashboard) refresh() {
+	d.mtx.Lock()
+	if d.loading {
+		d.mtx.Unlock()
+		return
+	}
+	d.loading = true
+	d.mtx.Unlock()
+
+	go func() {
+		rows, err := d.Rows()
+
+		d.mtx.Lock()
+		defer d.mtx.Unlock()
+
+		d.loading = false
+		if err != nil {
+			d.setStatus(color.RedString("failed to load: %s", err))
+		} else {
+			d.rows = rows
+		}
+
+		d.requestRedraw()
+	}()
+}
+
+// visibleRows are the rows matching the filter.
+func (d *Dashboard) visibleRows() []DashboardRow {
+	if d.filter == "" {
+		return d.rows
+	}
+
+	rows := []DashboardRow{}
+	for _, row := range d.rows {
+		if row.Item.Match(d.filter) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+func (d *Dashboard) help() string {
+	keys := []string{"↑/↓ move", "/ filter"}
+	for _, action := range d.Actions {
+		keys = append(keys, fmt.Sprintf("%c %s", action.Key, action.Name))
+	}
+	keys = append(keys, "[/] scroll log", "R refresh", "q quit")
+
+	return color.New(color.Faint).Sprint(strings.Join(keys, "  "))
+}
+
+func (d *Dashboard) draw(out io.Writer) {
+	width, height, err := readline.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	rows := d.visibleRows()
+	if d.selected >= len(rows) {
+		d.selected = len(rows) - 1
+	}
+	if d.selected < 0 {
+		d.selected = 0
+	}
+
+	var details []string
+	if len(rows) != 0 {
+		details = strings.Split(strings.Trim(rows[d.selected].Item.Details(), "\n"), "\n")
+	}
+
+	// title, headers and help take a line each, the rest is split between the list, the details and the log
+	available := height - 3
+	logHeight := available / 3
+	detailsHeight := len(details) + 1
+	if detailsHeight > available/4 {
+		detailsHeight = available / 4
+	}
+	listHeight := available - logHeight - detailsHeight
+	if listHeight < 1 {
+		listHeight = 1
+	}
+
+	if d.selected < d.offset {
+		d.offset = d.selected
+	} else if d.selected >= d.offset+listHeight {
+		d.offset = d.selected - listHeight + 1
+	}
+
+	lines := make([]string, 0, height)
+
+	title := color.New(color.Bold).Sprintf("* %s *", d.Title)
+	switch {
+	case d.confirm != nil && len(rows) != 0:
+		title += " " + color.YellowString(d.confirm.Confirm, rows[d.selected].Item.Selected()) + " [y/N]"
+	case d.filtering:
+		title += " /" + d.filter
+	case d.running != "":
+		title += " " + color.YellowString("%s...", d.running)
+	case d.loading:
+		title += " " + color.YellowString("loading...")
+	case d.status != "":
+		title += " " + d.status
+	}
+	lines = append(lines, title)
+
+	// the label and every column are as wide as their widest cell
+	widths := make([]int, len(d.Headers))
+	for i, header := range d.Headers {
+		widths[i] = visibleLen(header)
+	}
+	for _, row := range rows {
+		cells := append([]string{row.Item.Label()}, row.Columns...)
+		for i, cell := range cells {
+			if i < len(widths) && visibleLen(cell) > widths[i] {
+				widths[i] = visibleLen(cell)
+			}
+		}
+	}
+
+	renderRow := func(cells []string) string {
+		parts := make([]string, 0, len(cells))
+		for i, cell := range cells {
+			if i < len(widths) {
+				cell = padVisible(cell, widths[i])
+			}
+			parts = append(parts, cell)
+		}
+
+		return strings.Join(parts, "  ")
+	}
+
+	headers := make([]string, len(d.Headers))
+	for i, header := range d.Headers {
+		headers[i] = color.New(color.Faint).Sprint(header)
+	}
+	lines = append(lines, "  "+renderRow(headers))
+
+	for i := d.offset; i < d.offset+listHeight; i++ {
+		if i >= len(rows) {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := renderRow(append([]string{rows[i].Item.Label()}, rows[i].Columns...))
+		if i == d.selected {
+			line = color.CyanString("▸ ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	if detailsHeight > 0 {
+		for i := 0; i < detailsHeight; i++ {
+			if i < len(details) {
+				lines = append(lines, strings.ReplaceAll(details[i], "\t", "  "))
+			} else {
+				lines = append(lines, "")
+			}
+		}
+	}
+
+	lines = append(lines, color.New(color.Faint).Sprint(strings.Repeat("─", width)))
+	tail := d.Log.Tail(logHeight - 1)
+	for i := 0; i < logHeight-1; i++ {
+		if i < len(tail) {
+			lines = append(lines, tail[i])
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	lines = append(lines, d.help())
+
+	frame := strings.Builder{}
+	frame.WriteString(cursorHome)
+	for i, line := range lines {
+		if i >= height {
+			break
+		}
+
+		frame.WriteString(truncateVisible(line, width))
+		frame.WriteString(clearLine)
+		if i < height-1 {
+			frame.WriteString("\r\n")
+		}
+	}
+	frame.WriteString(clearBelow)
+
+	_, _ = io.WriteString(out, frame.String())
+}
+
+// run starts the action on the selected row, actions which do not suspend the screen run in the background.
+func (d *Dashboard) run(action *DashboardAction, item types.Selectable, suspend func(func())) {
+	if action.Suspend {
+		var err error
+		suspend(func() {
+			err = action.Run(item, &d.Log)
+		})
+
+		d.mtx.Lock()
+		if err != nil {
+			d.setStatus(color.RedString("%s %s failed: %s", action.Name, item.Selected(), err))
+		} else {
+			d.setStatus(color.GreenString("%s %s finished", action.Name, item.Selected()))
+		}
+		d.mtx.Unlock()
+
+		d.refresh()
+		return
+	}
+
+	d.mtx.Lock()
+	d.running = fmt.Sprintf("%s %s", action.Name, item.Selected())
+	d.mtx.Unlock()
+
+	fmt.Fprintf(&d.Log, "%s\n", color.New(color.Bold).Sprintf("$ %s %s", action.Name, item.Selected()))
+
+	go func() {
+		err := action.Run(item, &d.Log)
+
+		d.mtx.Lock()
+		if err != nil {
+			d.setStatus(color.RedString("%s failed: %s", d.running, err))
+		} else {
+			d.setStatus(color.GreenString("%s finished", d.running))
+		}
+		d.running = ""
+		d.mtx.Unlock()
+
+		d.refresh()
+	}()
+}
+
+// handleKey reacts to a key press, it returns true when the dashboard should close.
+func (d *Dashboard) handleKey(key []byte, suspend func(func())) bool {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	rows := d.visibleRows()
+	k := string(key)
+
+	if d.confirm != nil {
+		action := d.confirm
+		d.confirm = nil
+		if (k == "y" || k == "Y") && d.selected < len(rows) {
+			d.mtx.Unlock()
+			d.run(action, rows[d.selected].Item, suspend)
+			d.mtx.Lock()
+		} else {
+			d.setStatus("cancelled")
+		}
+
+		return false
+	}
+
+	if d.filtering {
+		switch {
+		case k == "\r" || k == "\n":
+			d.filtering = false
+		case k == "\x1b" || k == "\x03":
+			d.filtering = false
+			d.filter = ""
+		case k == "\x7f" || k == "\b":
+			if len(d.filter) > 0 {
+				_, size := utf8.DecodeLastRuneInString(d.filter)
+				d.filter = d.filter[:len(d.filter)-size]
+			}
+		case len(key) > 0 && key[0] >= ' ' && key[0] != 0x7f:
+			d.filter += k
+		}
+
+		d.selected = 0
+		return false
+	}
+
+	switch k {
+	case "q", "\x03":
+		return true
+	case "\x1b[A", "k":
+		d.selected--
+	case "\x1b[B", "j":
+		d.selected++
+	case "\x1b[5~":
+		d.selected -= 10
+	case "\x1b[6~":
+		d.selected += 10
+	case "/":
+		d.filtering = true
+		d.filter = ""
+	case "\x1b":
+		d.filter = ""
+	case "[":
+		d.Log.Scroll(5)
+	case "]":
+		d.Log.Scroll(-5)
+	case "R":
+		d.mtx.Unlock()
+		d.refresh()
+		d.mtx.Lock()
+	default:
+		action := d.action(key)
+		switch {
+		case action == nil || len(rows) == 0:
+		case d.running != "":
+			d.setStatus(color.YellowString("wait for %s to finish", d.running))
+		case action.Confirm != "":
+			d.confirm = action
+		default:
+			d.mtx.Unlock()
+			d.run(action, rows[d.selected].Item, suspend)
+			d.mtx.Lock()
+		}
+	}
+
+	return false
+}
+
+func (d *Dashboard) action(key []byte) *DashboardAction {
+	r, _ := utf8.DecodeRune(key)
+	for i := range d.Actions {
+		if d.Actions[i].Key == r {
+			return &d.Actions[i]
+		}
+	}
+
+	return nil
+}
+
+// Run shows the dashboard until it is closed with q or ctrl+c, the log output of helm-manager goes to the log pane meanwhile.
+func (d *Dashboard) Run() error {
+	fd := int(os.Stdin.Fd())
+	state, err := readline.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("the dashboard needs a terminal, %v", err)
+	}
+
+	out := color.Output
+	d.redraw = make(chan struct{}, 1)
+
+	var restoreMtx sync.Mutex
+	active := true
+	restore := func() {
+		restoreMtx.Lock()
+		defer restoreMtx.Unlock()
+
+		if active {
+			_, _ = io.WriteString(out, showCursor+leaveAltScreen)
+			_ = readline.Restore(fd, state)
+			active = false
+		}
+	}
+	enter := func() {
+		restoreMtx.Lock()
+		defer restoreMtx.Unlock()
+
+		if !active {
+			state, _ = readline.MakeRaw(fd)
+			_, _ = io.WriteString(out, enterAltScreen+hideCursor)
+			active = true
+		}
+	}
+
+	undoRedirect := logger.Redirect(&d.Log)
+
+	// a fatal error would otherwise leave the terminal in raw mode with the error hidden in the log pane
+	closed := false
+	logger.OnExit(func() {
+		if closed {
+			return
+		}
+
+		restore()
+		undoRedirect()
+
+		for _, line := range d.Log.Tail(10) {
+			_, _ = fmt.Fprintln(out, line)
+		}
+	})
+
+	defer func() {
+		restore()
+		undoRedirect()
+		closed = true
+	}()
+
+	_, _ = io.WriteString(out, enterAltScreen+hideCursor)
+
+	// keys are only read when asked for, so a suspended action such as an editor gets all the input
+	keys := make(chan []byte)
+	next := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 64)
+		for range next {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+
+			keys <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+	next <- struct{}{}
+
+	suspend := func(fn func()) {
+		restore()
+		fn()
+		enter()
+	}
+
+	d.refresh()
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		d.draw(out)
+
+		select {
+		case <-ticker.C:
+		case <-d.redraw:
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+
+			if d.handleKey(key, suspend) {
+				return nil
+			}
+
+			next <- struct{}{}
+		}
+	}
+}
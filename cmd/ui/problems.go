@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// maxAccepted is how many accepted values are listed for an argument, charts can have hundreds of versions.
+const maxAccepted = 10
+
+// argProblem is an argument which is missing or invalid while running without prompts.
+type argProblem struct {
+	arg rArgInterface
+	err error
+	// skipped arguments were not looked at, because an argument they depend on has a problem
+	skipped bool
+}
+
+type argProblems []argProblem
+
+func (p argProblems) has(arg rArgInterface) bool {
+	for _, problem := range p {
+		if problem.arg.Name() == arg.Name() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// failed returns the first of names which has a problem, empty when they are all fine.
+func (p argProblems) failed(names []string) string {
+	for _, name := range names {
+		for _, problem := range p {
+			if problem.arg.Name() == name {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+// report lists every problem along with how to pass the argument and the values it accepts.
+func (p argProblems) report(cmd *cobra.Command) error {
+	lines := make([]string, 0, len(p)*2+1)
+	if len(p) == 1 {
+		lines = append(lines, "1 argument is missing or invalid")
+	} else {
+		lines = append(lines, fmt.Sprintf("%d arguments are missing or invalid", len(p)))
+	}
+
+	for _, problem := range p {
+		lines = append(lines, fmt.Sprintf("  %s: %v", color.YellowString(argUsage(cmd, problem.arg)), problem.err))
+
+		if problem.skipped {
+			continue
+		}
+
+		if accepted := problem.arg.Completions(); len(accepted) > 0 {
+			more := ""
+			if len(accepted) > maxAccepted {
+				more = fmt.Sprintf(" and %d more", len(accepted)-maxAccepted)
+				accepted = accepted[:maxAccepted]
+			}
+
+			lines = append(lines, fmt.Sprintf("      accepted values: %s%s", strings.Join(accepted, ", "), more))
+		}
+	}
+
+	return fmt.Errorf("%s", strings.Join(lines, "\n"))
+}
+
+// argUsage is how an argument is passed, as a flag when the command has one named like it.
+func argUsage(cmd *cobra.Command, arg rArgInterface) string {
+	flag := cmd.Flags().Lookup(arg.Name())
+	switch {
+	case flag != nil && arg.Positional():
+		return fmt.Sprintf("--%s (or positional)", arg.Name())
+	case flag != nil:
+		return "--" + arg.Name()
+	default:
+		return fmt.Sprintf("<%s>", arg.Name())
+	}
+}
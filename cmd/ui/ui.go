@@ -170,3 +170,33 @@ func PromptUiSelectorNewFunc[T comparable](label string, longLabel string, resul
 		return "", ErrIgnoreValue
 	}
 }
+
+// PromptUiMultiSelectorFunc is PromptUiSelectorFunc for picking any number of options, result gets the indices of the picked options.
+func PromptUiMultiSelectorFunc(label string, longLabel string, result func([]int) error, options types.Future[[]types.Selectable]) func() ([]string, error) {
+	if longLabel == "" {
+		longLabel = label
+	} else if label == "" {
+		label = longLabel
+	}
+
+	if result == nil {
+		panic("result cannot be nil")
+	}
+	if options == nil {
+		panic("options cannot be nil")
+	}
+
+	return func() ([]string, error) {
+		items, err := options.Get()
+		if err != nil {
+			return nil, err
+		}
+
+		err = result(utils.MultiSelector(label, longLabel, true, items))
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ErrIgnoreValue
+	}
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 
@@ -17,6 +18,10 @@ var ErrIgnoreValue = errors.New("ignore value")
 
 func SubCommandRequired(next ...cobra.PositionalArgs) cobra.PositionalArgs {
 	return func(cmd *cobra.Command, args []string) error {
+		if inspect != nil {
+			return nil
+		}
+
 		if !UseInteractive() {
 			return cmdErrors.ErrSubcommandRequired
 		}
@@ -59,6 +64,10 @@ type Arg[T comparable] struct {
 	Validator  types.Validator[T]
 	UI         UiFunc[T]
 	Callback   func(T) error
+	// Completions are offered by shell completion when the validator does not know the values it accepts.
+	Completions types.Future[[]string]
+	// DependsOn names the arguments this one reads, it is not checked when one of them is missing or invalid.
+	DependsOn []string
 }
 
 func (p Arg[T]) ToRequiredArg() rArgInterface {
@@ -71,13 +80,15 @@ func (p Arg[T]) ToRequiredArg() rArgInterface {
 	}
 
 	return rArg[T]{
-		name:       p.Name,
-		ptr:        p.Ptr,
-		ui:         p.UI,
-		positional: p.Positional,
-		valid:      validator,
-		disabled:   p.Disabled,
-		callback:   p.Callback,
+		name:        p.Name,
+		ptr:         p.Ptr,
+		ui:          p.UI,
+		positional:  p.Positional,
+		valid:       validator,
+		disabled:    p.Disabled,
+		callback:    p.Callback,
+		completions: p.Completions,
+		dependsOn:   p.DependsOn,
 	}
 }
 
@@ -85,11 +96,13 @@ type rArg[T comparable] struct {
 	name string
 	ptr  *T
 
-	positional bool
-	valid      types.Validator[T]
-	ui         UiFunc[T]
-	disabled   types.Future[bool]
-	callback   func(T) error
+	positional  bool
+	valid       types.Validator[T]
+	ui          UiFunc[T]
+	disabled    types.Future[bool]
+	callback    func(T) error
+	completions types.Future[[]string]
+	dependsOn   []string
 }
 
 func (r rArg[T]) Disabled() bool {
@@ -131,10 +144,18 @@ func (r rArg[T]) Positional() bool {
 	return r.positional
 }
 
+func (r rArg[T]) Repeated() bool {
+	return false
+}
+
 func (r rArg[T]) Name() string {
 	return r.name
 }
 
+func (r rArg[T]) DependsOn() []string {
+	return r.dependsOn
+}
+
 func (r rArg[T]) HasUI() bool {
 	return r.ui != nil
 }
@@ -154,6 +175,10 @@ func (r rArg[T]) ToRequiredArg() rArgInterface {
 	return r
 }
 
+func (r rArg[T]) Completions() []string {
+	return completions(r.completions, r.valid)
+}
+
 func (r rArg[T]) Callback() error {
 	if r.callback == nil {
 		return nil
@@ -162,6 +187,137 @@ func (r rArg[T]) Callback() error {
 	return r.callback(*r.ptr)
 }
 
+// ListArg is an argument taking any number of values, as a positional argument it takes all remaining positional arguments
+// until one of them fails the validator, which is then left for the next argument.
+type ListArg struct {
+	Name       string
+	Ptr        *[]string
+	Disabled   types.Future[bool]
+	Positional bool
+	// Validator checks every value on its own.
+	Validator types.Validator[string]
+	UI        func() ([]string, error)
+	Callback  func([]string) error
+	// DependsOn names the arguments this one reads, it is not checked when one of them is missing or invalid.
+	DependsOn []string
+}
+
+func (l ListArg) ToRequiredArg() rArgInterface {
+	validator := l.Validator
+	if validator == nil {
+		validator = types.ValidatorFunction[string](func(v string) error {
+			return nil
+		})
+	}
+
+	return rListArg{
+		name:       l.Name,
+		ptr:        l.Ptr,
+		ui:         l.UI,
+		positional: l.Positional,
+		valid:      validator,
+		disabled:   l.Disabled,
+		callback:   l.Callback,
+		dependsOn:  l.DependsOn,
+	}
+}
+
+type rListArg struct {
+	name string
+	ptr  *[]string
+
+	positional bool
+	valid      types.Validator[string]
+	ui         func() ([]string, error)
+	disabled   types.Future[bool]
+	callback   func([]string) error
+	dependsOn  []string
+}
+
+func (r rListArg) Disabled() bool {
+	if r.disabled == nil {
+		return false
+	}
+
+	v, _ := r.disabled.Get()
+	return v
+}
+
+func (r rListArg) Empty() bool {
+	return r.ptr == nil || len(*r.ptr) == 0
+}
+
+func (r rListArg) Valid() error {
+	if r.Empty() {
+		// the same error a single argument gives when it is missing
+		return r.valid.Validate("")
+	}
+
+	for _, v := range *r.ptr {
+		if err := r.valid.Validate(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r rListArg) Set(arg string) error {
+	if err := r.valid.Validate(arg); err != nil {
+		return err
+	}
+
+	*r.ptr = append(*r.ptr, arg)
+	return nil
+}
+
+func (r rListArg) Positional() bool {
+	return r.positional
+}
+
+func (r rListArg) Repeated() bool {
+	return true
+}
+
+func (r rListArg) Name() string {
+	return r.name
+}
+
+func (r rListArg) DependsOn() []string {
+	return r.dependsOn
+}
+
+func (r rListArg) HasUI() bool {
+	return r.ui != nil
+}
+
+func (r rListArg) UI() error {
+	res, err := r.ui()
+	if err != nil && err != ErrIgnoreValue {
+		return err
+	} else if err == nil {
+		*r.ptr = res
+	}
+
+	return nil
+}
+
+func (r rListArg) ToRequiredArg() rArgInterface {
+	return r
+}
+
+func (r rListArg) Completions() []string {
+	return completions(nil, r.valid)
+}
+
+func (r rListArg) Callback() error {
+	if r.callback == nil {
+		return nil
+	}
+
+	return r.callback(*r.ptr)
+}
+
 type RequiredArg interface {
 	ToRequiredArg() rArgInterface
 }
@@ -170,14 +326,20 @@ type rArgInterface interface {
 	ToRequiredArg() rArgInterface
 
 	Name() string
+	// DependsOn are the names of the arguments this one reads.
+	DependsOn() []string
 	Empty() bool
 	Positional() bool
+	// Repeated arguments take as many positional arguments as they can.
+	Repeated() bool
 	Set(string) error
 	Valid() error
 	Callback() error
 	HasUI() bool
 	UI() error
 	Disabled() bool
+	// Completions are the values shell completion offers, nil when they are not known.
+	Completions() []string
 }
 
 var colorReset = color.New(color.Reset)
@@ -191,6 +353,66 @@ func fatalErr(err error, cmd *cobra.Command) {
 	logger.Fatal(err)
 }
 
+func hasPositional(rargs []rArgInterface) bool {
+	for _, parg := range rargs {
+		if parg.Positional() && parg.Empty() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setPositional assigns args to the empty positional arguments in order and returns the values left over,
+// along with the argument which took the last value. Without invalid the first value which does not fit is returned as an error,
+// with it every one is handed over and skipped.
+func setPositional(rargs []rArgInterface, args []string, invalid func(rArgInterface, error)) ([]string, rArgInterface, error) {
+	var filled rArgInterface
+	for i, parg := range rargs {
+		if len(args) == 0 {
+			break
+		}
+
+		if !parg.Empty() || !parg.Positional() || parg.Disabled() {
+			continue
+		}
+
+		arg := args[0]
+		args = args[1:]
+
+		if err := parg.Set(arg); err != nil {
+			if invalid == nil {
+				return nil, nil, err
+			}
+
+			invalid(parg, err)
+			continue
+		}
+
+		filled = parg
+
+		// a value which does not fit is left for the next positional argument, without one it is an error
+		last := !hasPositional(rargs[i+1:])
+		for parg.Repeated() && len(args) > 0 {
+			if err := parg.Set(args[0]); err != nil {
+				if !last {
+					break
+				}
+
+				if invalid == nil {
+					return nil, nil, err
+				}
+
+				invalid(parg, err)
+			}
+
+			args = args[1:]
+		}
+	}
+
+	return args, filled, nil
+}
+
 func PositionalArgs(aargs []RequiredArg, preHook func(cmd *cobra.Command)) cobra.PositionalArgs {
 	rargs := make([]rArgInterface, len(aargs))
 	for i, a := range aargs {
@@ -198,37 +420,60 @@ func PositionalArgs(aargs []RequiredArg, preHook func(cmd *cobra.Command)) cobra
 	}
 
 	return func(cmd *cobra.Command, args []string) error {
+		if inspect != nil {
+			inspect(rargs)
+			return nil
+		}
+
 		if preHook != nil {
 			preHook(cmd)
 		}
 
-		for _, parg := range rargs {
-			if len(args) == 0 {
-				break
+		if _, err := answers.Get(); err != nil {
+			fatalErr(err, cmd)
+		}
+
+		// without prompts every problem is collected, so they can all be fixed at once
+		interactive := UseInteractive()
+		problems := argProblems{}
+		invalid := func(parg rArgInterface, err error) {
+			if interactive {
+				fatalErr(err, cmd)
 			}
 
-			if !parg.Empty() || !parg.Positional() || parg.Disabled() {
-				continue
+			// the values an argument reads have to be valid before it can be looked at
+			if dep := problems.failed(parg.DependsOn()); dep != "" {
+				problems = append(problems, argProblem{arg: parg, err: fmt.Errorf("not checked because %s is invalid", dep), skipped: true})
+				return
 			}
 
-			arg := args[0]
-			args = args[1:]
+			problems = append(problems, argProblem{arg: parg, err: err})
+		}
 
-			if err := parg.Set(arg); err != nil {
-				fatalErr(err, cmd)
-			}
+		args, _, err := setPositional(rargs, args, invalid)
+		if err != nil {
+			fatalErr(err, cmd)
 		}
 
 		if len(args) > 0 {
 			return cmdErrors.ErrUnexpectedArgs
 		}
 
-		for _, parg := range rargs {
-			if parg.Disabled() {
-				continue
+		check := func(parg rArgInterface) {
+			if parg.Disabled() || problems.has(parg) {
+				return
 			}
 
-			if parg.Empty() && UseInteractive() && parg.HasUI() {
+			answered := false
+			if parg.Empty() {
+				var err error
+				if answered, err = answer(parg); err != nil {
+					invalid(parg, err)
+					return
+				}
+			}
+
+			if parg.Empty() && !answered && interactive && parg.HasUI() {
 				err := parg.UI()
 				if err != nil {
 					fatalErr(err, cmd)
@@ -236,14 +481,29 @@ func PositionalArgs(aargs []RequiredArg, preHook func(cmd *cobra.Command)) cobra
 			}
 
 			if err := parg.Valid(); err != nil {
-				fatalErr(err, cmd)
+				invalid(parg, err)
+				return
 			}
 
+			// callbacks still run after other problems, they report errors too, the loop below skips the ones whose dependencies failed
 			if err := parg.Callback(); err != nil {
-				fatalErr(err, cmd)
+				invalid(parg, err)
 			}
 		}
 
+		for _, parg := range rargs {
+			if !problems.has(parg) && problems.failed(parg.DependsOn()) != "" {
+				invalid(parg, nil)
+				continue
+			}
+
+			check(parg)
+		}
+
+		if len(problems) > 0 {
+			fatalErr(problems.report(cmd), cmd)
+		}
+
 		return nil
 	}
 }
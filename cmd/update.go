@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/fatih/color"
 	"github.com/seventv/helm-manager/v2/cmd/ui"
@@ -18,48 +17,53 @@ func init() {
 	rootCmd.AddCommand(updateCmd)
 
 	{
-		updateCmd.Flags().StringVar(&Args.Name, "name", "", "Update the name of the release")
+		updateCmd.Flags().StringSliceVar(&Args.Names, "name", nil, "Name of the release to update, can be repeated")
+		updateCmd.Flags().StringVarP(&Args.Selector, "selector", "l", "", "Update the releases matching the selector to the newest version, or list their updates with --list, like tier=backend,team!=data")
 		updateCmd.Flags().StringVar(&Args.UpdateCmd.Version, "version", "", "Version to update to")
 		updateCmd.Flags().BoolVar(&Args.Deploy, "deploy", false, "Deploy the updated release to the cluster")
 		updateCmd.Flags().BoolVar(&Args.UpdateCmd.List, "list", false, "List all available versions")
+		updateCmd.Flags().BoolVar(&Args.UpdateCmd.All, "all", false, "Update every release to the newest version of its chart")
 	}
 }
 
+// updateNewest is set when several releases are updated, they all move to the newest version of their chart.
+var updateNewest = types.FutureFromFunc(func() bool {
+	return Args.UpdateCmd.All || Args.UpdateCmd.List || Args.Selector != "" || len(Args.Names) > 1
+})
+
+// updateTarget is the release a version is picked for, the version argument is only used with a single release.
+func updateTarget() types.ManifestRelease {
+	if len(Args.Names) == 0 {
+		return types.ManifestRelease{}
+	}
+
+	return Manifest.ReleaseByName(Args.Names[0])
+}
+
 var updateCmd = &cobra.Command{
-	Use:   "update",
-	Short: "Update an existing release, or list available versions",
-	Long:  "Update an existing release, or list available versions",
+	Use:     "update",
+	Short:   "Update an existing release, or list available versions",
+	Long:    "Update existing releases, or list available versions. A single release can be moved to any version, several releases move to the newest version of their chart",
+	Example: "   helm-manager update [NAME...] [VERSION]\n   helm-manager update mongo 13.6.2\n   helm-manager update mongo redis\n   helm-manager update -l tier=backend",
 	Args: ui.PositionalArgs([]ui.RequiredArg{
-		ui.Arg[string]{
-			Name:       "name",
-			Ptr:        &Args.Name,
-			Positional: true,
-			Disabled:   types.FutureFromPtr(&Args.UpdateCmd.List),
-			Validator:  types.EqualValidator(types.ToStringer(`"%s" is not a release in the manifest`), types.FutureFromStringers(types.FutureFromPtr(&Manifest.Releases))),
-			UI: ui.PromptUiSelectorFunc[string]("Release", "Which release would you like to update", func(i int) error {
-				Args.Name = Manifest.Releases[i].Name
-				return nil
-			}, types.FutureInterfacerArray[types.ManifestRelease, types.Selectable](types.FutureFromPtr(&Manifest.Releases))),
-			Callback: func(s string) error {
-				Args.Name = strings.ToLower(s)
-
-				return nil
-			},
-		},
+		releaseNamesArg("Which releases would you like to update", types.FutureFromFunc(func() bool {
+			return Args.UpdateCmd.All || Args.UpdateCmd.List || Args.Selector != ""
+		})),
 		ui.Arg[string]{
 			Name:       "version",
 			Ptr:        &Args.UpdateCmd.Version,
 			Positional: true,
-			Disabled:   types.FutureFromPtr(&Args.UpdateCmd.List),
+			Disabled:   updateNewest,
+			DependsOn:  []string{"name"},
 			Validator: types.EqualValidator(types.StringerFunc(func() string {
-				return fmt.Sprintf("\"%s\" is not a valid version for the chart \"%s\"", "%s", Manifest.ReleaseByName(Args.Name).Chart.RepoName())
+				return fmt.Sprintf("\"%s\" is not a valid version for the chart \"%s\"", "%s", updateTarget().Chart.RepoName())
 			}), types.FutureFromFuncErr(func() ([]string, error) {
 				charts, err := HelmChartsFuture.Get()
 				if err != nil {
 					return nil, err
 				}
 
-				release := Manifest.ReleaseByName(Args.Name)
+				release := updateTarget()
 				for _, chart := range charts {
 					if release.Chart.RepoName() == chart.RepoName {
 						versions := make([]string, len(chart.Versions))
@@ -78,7 +82,7 @@ var updateCmd = &cobra.Command{
 					return err
 				}
 
-				release := Manifest.ReleaseByName(Args.Name)
+				release := updateTarget()
 				for _, chart := range versions {
 					if release.Chart.RepoName() == chart.RepoName {
 						Args.UpdateCmd.Version = chart.Versions[i].Version
@@ -93,7 +97,7 @@ var updateCmd = &cobra.Command{
 					return nil, err
 				}
 
-				release := Manifest.ReleaseByName(Args.Name)
+				release := updateTarget()
 				for _, chart := range charts {
 					if release.Chart.RepoName() == chart.RepoName {
 						return chart.Versions, nil
@@ -129,13 +133,28 @@ var updateCmd = &cobra.Command{
 			}
 
 			updates := false
-			for _, release := range Manifest.Releases {
+			for _, release := range filteredReleases() {
 				chart, ok := mapChart[release.Chart.RepoName()]
 				if !ok {
 					logger.Warnf("no chart found for release %s", release.Name)
 					continue
 				}
 
+				if release.Chart.Repo == "" && chart.IsLocal {
+					if utils.CompareVersions(chart.Version, release.Chart.Version) > 0 {
+						updates = true
+						logger.Infof(
+							"Release %s uses local chart %s which moved ahead from %s to %s\n   The next deploy will use the new version",
+							color.CyanString(release.Name),
+							color.YellowString(release.Chart.RepoName()),
+							color.RedString(release.Chart.Version),
+							color.GreenString(chart.Version),
+						)
+					}
+
+					continue
+				}
+
 				if chart.Version != release.Chart.Version {
 					updates = true
 					appVersionUpdate := ""
@@ -161,7 +180,17 @@ var updateCmd = &cobra.Command{
 			return
 		}
 
-		release, idx := Manifest.ReleaseIdxByName(Args.Name)
+		if Args.UpdateCmd.All {
+			updateNewestReleases(charts, filteredReleases())
+			return
+		}
+
+		if newest, _ := updateNewest.Get(); newest {
+			updateNewestReleases(charts, selectedReleases())
+			return
+		}
+
+		release := updateTarget()
 
 		multiChart := types.HelmChartMultiArray(charts).FindChart(release.Chart.RepoName())
 		chart := multiChart.FindVersion(Args.UpdateCmd.Version)
@@ -169,42 +198,117 @@ var updateCmd = &cobra.Command{
 			logger.Fatalf("no version %s found for %s", Args.UpdateCmd.Version, release.Chart.RepoName())
 		}
 
-		data, err := utils.ReadFile(ReleasePath(Args.Name))
+		multiChart.HelmChart = types.HelmChart(chart)
+		updateRelease(release, multiChart)
+	},
+}
+
+// updateNewestReleases moves the releases using a repo chart to the newest version of the chart.
+func updateNewestReleases(charts []types.HelmChartMulti, releases []types.ManifestRelease) {
+	type update struct {
+		release types.ManifestRelease
+		chart   types.HelmChartMulti
+	}
+
+	updates := []update{}
+	needed := []types.HelmChart{}
+	for _, release := range releases {
+		multiChart := types.HelmChartMultiArray(charts).FindChart(release.Chart.RepoName())
+		if multiChart.RepoName == "" {
+			logger.Warnf("no chart found for release %s", release.Name)
+			continue
+		}
+
+		// local charts are followed by the next deploy
+		if multiChart.IsLocal || utils.CompareVersions(multiChart.Version, release.Chart.Version) <= 0 {
+			continue
+		}
+
+		data, err := utils.ReadFile(ReleasePath(release.Name))
 		if err != nil {
 			logger.Fatalf("failed to read release file: %s", err)
 		}
 
-		multiChart.HelmChart = types.HelmChart(chart)
-		result, err := UpgradeDocument(data, multiChart, true)
+		updates = append(updates, update{release: release, chart: multiChart})
+		needed = append(needed, UpgradeDocumentCharts(data, multiChart)...)
+	}
+
+	if len(updates) == 0 {
+		logger.Info("No updates available")
+		return
+	}
+
+	PrefetchChartValues(needed)
+
+	for _, u := range updates {
+		updateRelease(u.release, u.chart)
+	}
+}
+
+// updateRelease moves a release to the chart version of multiChart, rewriting its release file and optionally deploying it.
+func updateRelease(release types.ManifestRelease, multiChart types.HelmChartMulti) {
+	_, idx := Manifest.ReleaseIdxByName(release.Name)
+	oldVersion := release.Chart.Version
+	chart := multiChart.HelmChart
+
+	data, err := utils.ReadFile(ReleasePath(release.Name))
+	if err != nil {
+		logger.Fatalf("failed to read release file: %s", err)
+	}
+
+	result, err := UpgradeDocument(data, multiChart, true)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	release.Chart = types.ManifestChart{
+		Name:       chart.Name(),
+		Version:    chart.Version,
+		AppVersion: chart.AppVersion,
+		Repo:       chart.Repo(),
+	}
+
+	Manifest.Releases[idx] = release
+
+	chartToDeploy := types.HelmChart(chart)
+	if Args.Deploy {
+		chartToDeploy = vendorForDeploy(release, chartToDeploy)
+
+		var cleanup func()
+		chartToDeploy, cleanup, err = LockChart(result, chartToDeploy)
+		defer cleanup()
 		if err != nil {
 			logger.Fatal(err)
 		}
+	}
 
-		release.Chart = types.ManifestChart{
-			Name:       chart.Name(),
-			Version:    chart.Version,
-			AppVersion: chart.AppVersion,
-			Repo:       chart.Repo(),
+	if !Args.DryRun {
+		if err = os.WriteFile(ReleasePath(release.Name), result.Document, 0644); err != nil {
+			logger.Fatalf("failed to write release file: %s", err)
 		}
+		utils.WriteManifest(Args.Context)
+	} else {
+		logger.Info("Dry run, not writing manifest or release file")
+	}
 
-		Manifest.Releases[idx] = release
+	GarbageCollectVendor()
 
-		if !Args.DryRun {
-			if err = os.WriteFile(ReleasePath(Args.Name), result.Document, 0644); err != nil {
-				logger.Fatalf("failed to write release file: %s", err)
-			}
-			utils.WriteManifest(Args.Context)
-		} else {
-			logger.Info("Dry run, not writing manifest or release file")
-		}
+	Audit(types.AuditEntry{
+		Action:     types.AuditActionUpdate,
+		Kind:       "release",
+		Name:       release.Name,
+		Namespace:  release.Namespace,
+		OldVersion: oldVersion,
+		NewVersion: release.Chart.Version,
+		ValuesHash: ValuesHash(result.EnvSubbedValues),
+	}, nil)
 
-		if Args.Deploy {
-			err = DeployRelease(release, types.HelmChart(chart), result.EnvSubbedValues, result.EnvSubbedDocument)
-			if err != nil {
-				logger.Fatal(err)
-			}
+	if Args.Deploy {
+		err = DeployRelease(release, chartToDeploy, result.EnvSubbedValues, result.EnvSubbedDocument)
+		if err != nil {
+			logger.Fatal(err)
 		}
+	}
 
-		logger.Infof("Release %s (%s) updated", Args.Name, release.Chart.RepoName())
-	},
+	logger.Infof("Release %s (%s) updated", release.Name, release.Chart.RepoName())
 }
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/seventv/helm-manager/v2/external"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	rootCmd.PersistentFlags().IntVar(&Args.Jobs, "jobs", 4, "How many charts to fetch at the same time")
+}
+
+// chartValues dedupes `helm show values`, every chart version is fetched at most once per run.
+var chartValues = struct {
+	mtx     sync.Mutex
+	futures map[string]types.Future[[]byte]
+}{
+	futures: map[string]types.Future[[]byte]{},
+}
+
+func chartValuesFuture(chart types.HelmChart) types.Future[[]byte] {
+	key := fmt.Sprintf("%s@%s", chart.HelmName(), chart.Version)
+
+	chartValues.mtx.Lock()
+	defer chartValues.mtx.Unlock()
+
+	if f, ok := chartValues.futures[key]; ok {
+		return f
+	}
+
+	f := types.FutureFromFuncErr(func() ([]byte, error) {
+		ctx, cancel := OperationContext(types.TimeoutQuery)
+		defer cancel()
+
+		return external.Helm.ShowValues(ctx, chart)
+	})
+	chartValues.futures[key] = f
+
+	return f
+}
+
+// ChartDefaultValues returns the default values of a chart version.
+// Every caller gets its own parsed document, since UpgradeDocument modifies them.
+func ChartDefaultValues(chart types.HelmChart) (*yaml.Node, error) {
+	data, err := chartValuesFuture(chart).Get()
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, data)
+	}
+
+	return utils.ParseYaml(data)
+}
+
+// UpgradeDocumentCharts are the chart versions UpgradeDocument needs the default values of, the new version and the locked one if it changed.
+func UpgradeDocumentCharts(data []byte, chart types.HelmChartMulti) []types.HelmChart {
+	charts := []types.HelmChart{chart.HelmChart}
+
+	// anything unreadable is reported by UpgradeDocument itself
+	node, err := utils.ParseYaml(data)
+	if err != nil || len(node.Content) != 3 {
+		return charts
+	}
+
+	lock := types.ReleaseLock{}
+	if err := node.Content[0].Decode(&lock); err != nil || lock.Version == "" || lock.Version == chart.Version {
+		return charts
+	}
+
+	if oldVersion := chart.FindVersion(lock.Version); oldVersion.Version != "" {
+		charts = append(charts, types.HelmChart(oldVersion))
+	}
+
+	return charts
+}
+
+// PrefetchChartValues fetches the default values of the charts up front, --jobs of them at a time.
+// Failures are not reported here, UpgradeDocument returns them when it needs the values.
+func PrefetchChartValues(charts []types.HelmChart) {
+	futures := []types.Future[[]byte]{}
+	seen := map[types.Future[[]byte]]bool{}
+	for _, chart := range charts {
+		f := chartValuesFuture(chart)
+		if !seen[f] {
+			seen[f] = true
+			futures = append(futures, f)
+		}
+	}
+
+	if len(futures) == 0 {
+		return
+	}
+
+	var finished, failed int32
+	done := utils.Loader(utils.LoaderOptions{
+		FetchingText: fmt.Sprintf("Fetching values of %d charts", len(futures)),
+		SuccessText:  fmt.Sprintf("Fetched values of %d charts", len(futures)),
+		FailureText:  fmt.Sprintf("Failed to fetch values of some of the %d charts", len(futures)),
+		Progress: func() string {
+			return fmt.Sprintf("%d/%d", atomic.LoadInt32(&finished), len(futures))
+		},
+	})
+
+	jobs := Args.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	sem := make(chan struct{}, jobs)
+	wg := sync.WaitGroup{}
+	for _, f := range futures {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(f types.Future[[]byte]) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if _, err := f.Get(); err != nil {
+				atomic.AddInt32(&failed, 1)
+			}
+			atomic.AddInt32(&finished, 1)
+		}(f)
+	}
+
+	wg.Wait()
+	done(failed == 0)
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func init() {
+	rootCmd.AddCommand(vendorCmd)
+
+	vendorCmd.Flags().BoolVarP(&Args.DeployCmd.AcceptNewDigest, "accept-new-digest", "", false, "Vendor charts whose archive digest changed since they were locked")
+}
+
+func VendorDir() string {
+	return path.Join(Args.Context, "charts")
+}
+
+// VendorPath is where the archive of a repo chart is stored when it is vendored.
+func VendorPath(chart types.ManifestChart) string {
+	return path.Join(VendorDir(), strings.ToLower(chart.Repo), fmt.Sprintf("%s-%s.tgz", chart.Name, chart.Version))
+}
+
+func isVendored(chart types.HelmChart) bool {
+	return chart.IsLocal && strings.HasSuffix(chart.LocalPath, ".tgz")
+}
+
+// VendoredChartsFuture lists the vendored archives of the releases in the manifest, named like the repo charts they replace.
+var VendoredChartsFuture = types.FutureFromFunc(func() []types.HelmChartMulti {
+	chartMp := map[string]*types.HelmChartMulti{}
+	charts := []types.HelmChartMulti{}
+
+	for _, release := range Manifest.Releases {
+		if release.Chart.Repo == "" {
+			continue
+		}
+
+		archive := VendorPath(release.Chart)
+		if _, err := os.Stat(archive); err != nil {
+			logger.Warnf("chart %s %s of release %s is not vendored, run `helm-manager vendor`", release.Chart.RepoName(), release.Chart.Version, release.Name)
+			continue
+		}
+
+		chart := types.HelmChart{
+			RepoName:   release.Chart.RepoName(),
+			Version:    release.Chart.Version,
+			AppVersion: release.Chart.AppVersion,
+			LocalPath:  archive,
+			IsLocal:    true,
+		}
+
+		if c, ok := chartMp[chart.RepoName]; ok {
+			c.Versions = append(c.Versions, types.HelmChartMultiVersion(chart))
+		} else {
+			chartMp[chart.RepoName] = &types.HelmChartMulti{
+				HelmChart: chart,
+				Versions:  []types.HelmChartMultiVersion{types.HelmChartMultiVersion(chart)},
+			}
+		}
+	}
+
+	for _, c := range chartMp {
+		charts = append(charts, *c)
+	}
+
+	return charts
+})
+
+// DeployChartsFuture are the charts deploys use, in vendor mode they never reach a repo.
+var DeployChartsFuture = types.FutureFromFuncErr(func() ([]types.HelmChartMulti, error) {
+	if !Manifest.Vendor {
+		return HelmChartsFuture.Get()
+	}
+
+	return append(VendoredChartsFuture.GetOrPanic(), LocalChartsFuture.GetOrPanic()...), nil
+})
+
+// deployReposIfNeeded makes sure the repos exist before deploying, vendored deploys do not need them.
+func deployReposIfNeeded() {
+	if Manifest.Vendor {
+		logger.Info("Vendor mode, not deploying repos")
+		return
+	}
+
+	deployReposHelper()
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+func readReleaseLock(release types.ManifestRelease) types.ReleaseLock {
+	lock := types.ReleaseLock{}
+
+	data, err := os.ReadFile(ReleasePath(release.Name))
+	if err != nil {
+		return lock
+	}
+
+	node, err := utils.ParseYaml(data)
+	if err != nil || len(node.Content) != 3 {
+		return lock
+	}
+
+	_ = node.Content[0].Decode(&lock)
+
+	return lock
+}
+
+// vendorForDeploy vendors the chart of a release which is about to be deployed and returns the chart to deploy it from.
+// A dry run does not store the archive, so the chart is deployed from its repo instead.
+func vendorForDeploy(release types.ManifestRelease, chart types.HelmChart) types.HelmChart {
+	if !Manifest.Vendor || release.Chart.Repo == "" {
+		return chart
+	}
+
+	if err := VendorRelease(release); err != nil {
+		logger.Fatal(err)
+	}
+
+	if !Args.DryRun {
+		chart.IsLocal = true
+		chart.LocalPath = VendorPath(release.Chart)
+	}
+
+	return chart
+}
+
+// VendorRelease downloads the chart of a release into the vendor directory, checking it against the digest in the lock.
+func VendorRelease(release types.ManifestRelease) error {
+	if release.Chart.Repo == "" {
+		return nil
+	}
+
+	target := VendorPath(release.Chart)
+	lock := readReleaseLock(release)
+	locked := lock.Chart == release.Chart.RepoName() && lock.Version == release.Chart.Version && lock.Digest != ""
+
+	if data, err := os.ReadFile(target); err == nil {
+		if !locked || lock.Digest == ArchiveDigest(data) {
+			logger.Infof("%s %s already vendored", release.Chart.RepoName(), release.Chart.Version)
+			return nil
+		}
+
+		logger.Warnf("vendored %s %s does not match the lock, downloading it again", release.Chart.RepoName(), release.Chart.Version)
+	}
+
+	keyring := ""
+	if Manifest.Keyring != "" {
+		keyring = utils.MergeRelativePath(Args.Context, Manifest.Keyring)
+	}
+
+	done := utils.Loader(utils.LoaderOptions{
+		FetchingText: fmt.Sprintf("Vendoring chart %s %s", release.Chart.RepoName(), release.Chart.Version),
+		SuccessText:  fmt.Sprintf("Vendored chart %s %s", release.Chart.RepoName(), release.Chart.Version),
+		FailureText:  fmt.Sprintf("Failed to vendor chart %s %s", release.Chart.RepoName(), release.Chart.Version),
+	})
+
+	archive, resp, cleanup, err := pullChartArchive(types.HelmChart{RepoName: release.Chart.RepoName(), Version: release.Chart.Version}, keyring)
+	defer cleanup()
+	if err != nil {
+		done(false)
+		return fmt.Errorf("failed to pull chart %s %s: %v\n%s", release.Chart.RepoName(), release.Chart.Version, err, resp)
+	}
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		done(false)
+		return err
+	}
+
+	if digest := ArchiveDigest(data); locked && digest != lock.Digest {
+		if !Args.DeployCmd.AcceptNewDigest {
+			done(false)
+			return fmt.Errorf("the archive of %s %s changed since it was locked\n   locked:  %s\n   current: %s\nthe repo republished this version, if this is expected run with %s", lock.Chart, lock.Version, lock.Digest, digest, color.YellowString("--accept-new-digest"))
+		}
+
+		logger.Warnf("accepting new digest %s for %s %s, the lock is updated on the next deploy", digest, lock.Chart, lock.Version)
+	}
+
+	if Args.DryRun {
+		done(true)
+		return nil
+	}
+
+	if err := os.MkdirAll(path.Dir(target), 0755); err != nil {
+		done(false)
+		return err
+	}
+
+	if err := copyFile(archive, target); err != nil {
+		done(false)
+		return err
+	}
+
+	// helm pull --verify keeps the provenance file next to the archive, deploys verify against it offline
+	if _, err := os.Stat(archive + ".prov"); err == nil {
+		if err := copyFile(archive+".prov", target+".prov"); err != nil {
+			done(false)
+			return err
+		}
+	}
+
+	done(true)
+
+	return nil
+}
+
+// GarbageCollectVendor removes vendored archives which no release in the manifest uses anymore.
+// Without vendor: true charts/ only holds local charts and archives put there by hand, so nothing is removed.
+func GarbageCollectVendor() {
+	if !Manifest.Vendor {
+		return
+	}
+
+	if _, err := os.Stat(VendorDir()); err != nil {
+		return
+	}
+
+	used := map[string]bool{}
+	for _, release := range Manifest.Releases {
+		if release.Chart.Repo != "" {
+			used[filepath.Clean(VendorPath(release.Chart))] = true
+		}
+	}
+
+	_ = filepath.Walk(VendorDir(), func(pth string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		archive := strings.TrimSuffix(pth, ".prov")
+		if !strings.HasSuffix(archive, ".tgz") || used[filepath.Clean(archive)] {
+			return nil
+		}
+
+		// local charts can live in charts/ as well, only archives in a repo directory are vendored
+		if filepath.Dir(filepath.Dir(pth)) != filepath.Clean(VendorDir()) {
+			return nil
+		}
+
+		if Args.DryRun {
+			logger.Infof("Dry run, not removing stale vendored chart %s", pth)
+			return nil
+		}
+
+		if err := os.Remove(pth); err != nil {
+			logger.Warnf("failed to remove stale vendored chart %s: %s", pth, err)
+		} else {
+			logger.Infof("Removed stale vendored chart %s", pth)
+		}
+
+		return nil
+	})
+
+	entries, _ := os.ReadDir(VendorDir())
+	for _, entry := range entries {
+		if !entry.IsDir() || Args.DryRun {
+			continue
+		}
+
+		if children, err := os.ReadDir(path.Join(VendorDir(), entry.Name())); err == nil && len(children) == 0 {
+			_ = os.Remove(path.Join(VendorDir(), entry.Name()))
+		}
+	}
+}
+
+var vendorCmd = &cobra.Command{
+	Use:     "vendor",
+	Short:   "Download the chart of every release into the context",
+	Long:    "Download the locked chart version of every release into charts/, with vendor: true in the manifest deploys use these archives and never reach a repo",
+	Example: "   helm-manager vendor\n   helm-manager vendor --accept-new-digest",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		zap.S().Infof("* %s *", color.BlueString("Helm Manager Vendor"))
+
+		ManifestExist(cmd)
+
+		deployReposHelper()
+
+		for _, release := range Manifest.Releases {
+			if err := VendorRelease(release); err != nil {
+				logger.Fatal(err)
+			}
+		}
+
+		GarbageCollectVendor()
+
+		if !Manifest.Vendor {
+			logger.Warnf("vendor is not enabled in the manifest, deploys still use the repos, set %s to use the vendored charts", color.YellowString("vendor: true"))
+		}
+
+		logger.Info("charts vendored")
+	},
+}
@@ -0,0 +1,87 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/seventv/helm-manager/v2/utils"
+)
+
+type _git struct{}
+
+var Git = _git{}
+
+func (_git) HeadCommit(ctx context.Context, dir string) (string, error) {
+	resp, err := utils.ExecuteCommand(ctx, "git", "-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(resp)), nil
+}
+
+func (_git) IsDirty(ctx context.Context, dir string) (bool, error) {
+	resp, err := utils.ExecuteCommand(ctx, "git", "-C", dir, "status", "--porcelain", "--", ".")
+	if err != nil {
+		return false, err
+	}
+
+	return len(strings.TrimSpace(string(resp))) != 0, nil
+}
+
+func (_git) TopLevel(ctx context.Context, dir string) (string, error) {
+	resp, err := utils.ExecuteCommand(ctx, "git", "-C", dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(resp)), nil
+}
+
+func (_git) VerifyRef(ctx context.Context, dir string, ref string) error {
+	resp, err := utils.ExecuteCommand(ctx, "git", "-C", dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("unknown git ref %s\n%s", ref, resp)
+	}
+
+	return nil
+}
+
+// ChangedFiles returns the files which differ between ref and the working tree, including untracked files which are not ignored.
+// The paths are relative to the top level of the repository.
+func (_git) ChangedFiles(ctx context.Context, dir string, ref string) ([]string, error) {
+	resp, err := utils.ExecuteCommand(ctx, "git", "-C", dir, "diff", "--name-only", "--no-renames", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, resp)
+	}
+
+	untracked, err := utils.ExecuteCommand(ctx, "git", "-C", dir, "ls-files", "--others", "--exclude-standard", "--full-name", "--", ":/")
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, untracked)
+	}
+
+	files := []string{}
+	for _, line := range strings.Split(string(resp)+"\n"+string(untracked), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+
+	return files, nil
+}
+
+// Show returns the content of a file at ref, the path is relative to dir.
+// The returned bool is false if the file did not exist at ref.
+func (_git) Show(ctx context.Context, dir string, ref string, pth string) ([]byte, bool, error) {
+	if _, err := utils.ExecuteCommand(ctx, "git", "-C", dir, "cat-file", "-e", fmt.Sprintf("%s:./%s", ref, pth)); err != nil {
+		return nil, false, nil
+	}
+
+	resp, err := utils.ExecuteCommand(ctx, "git", "-C", dir, "show", fmt.Sprintf("%s:./%s", ref, pth))
+	if err != nil {
+		return nil, false, fmt.Errorf("%v\n%s", err, resp)
+	}
+
+	return resp, true, nil
+}
@@ -2,7 +2,9 @@ package external
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"strconv"
 
 	"github.com/seventv/helm-manager/v2/types"
 	"github.com/seventv/helm-manager/v2/utils"
@@ -13,16 +15,16 @@ type _helm struct{}
 
 var Helm = _helm{}
 
-func (_helm) AddRepo(repo types.ManifestRepo) ([]byte, error) {
-	return utils.ExecuteCommand("helm", "repo", "add", repo.Name, repo.URL)
+func (_helm) AddRepo(ctx context.Context, repo types.ManifestRepo) ([]byte, error) {
+	return utils.ExecuteCommand(ctx, "helm", "repo", "add", repo.Name, repo.URL)
 }
 
-func (_helm) UpdateRepos() ([]byte, error) {
-	return utils.ExecuteCommand("helm", "repo", "update")
+func (_helm) UpdateRepos(ctx context.Context) ([]byte, error) {
+	return utils.ExecuteCommand(ctx, "helm", "repo", "update")
 }
 
-func (_helm) ListRepos() ([]types.HelmRepo, error) {
-	resp, err := utils.ExecuteCommand("helm", "repo", "list", "-o", "json")
+func (_helm) ListRepos(ctx context.Context) ([]types.HelmRepo, error) {
+	resp, err := utils.ExecuteCommand(ctx, "helm", "repo", "list", "-o", "json")
 	if err != nil {
 		return nil, err
 	}
@@ -35,8 +37,8 @@ func (_helm) ListRepos() ([]types.HelmRepo, error) {
 	return repos, nil
 }
 
-func (_helm) ListReleases() ([]types.HelmRelease, error) {
-	resp, err := utils.ExecuteCommand("helm", "list", "-o", "json", "--all-namespaces", "--deployed")
+func (_helm) ListReleases(ctx context.Context) ([]types.HelmRelease, error) {
+	resp, err := utils.ExecuteCommand(ctx, "helm", "list", "-o", "json", "--all-namespaces", "--deployed")
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +51,8 @@ func (_helm) ListReleases() ([]types.HelmRelease, error) {
 	return releases, nil
 }
 
-func (_helm) ListCharts() ([]types.HelmChartMulti, error) {
-	resp, err := utils.ExecuteCommand("helm", "search", "repo", "--output", "json", "--versions")
+func (_helm) ListCharts(ctx context.Context) ([]types.HelmChartMulti, error) {
+	resp, err := utils.ExecuteCommand(ctx, "helm", "search", "repo", "--output", "json", "--versions")
 	if err != nil {
 		return nil, err
 	}
@@ -80,8 +82,8 @@ func (_helm) ListCharts() ([]types.HelmChartMulti, error) {
 	return helmChartMultiList, nil
 }
 
-func (_helm) GetReleaseValues(release types.HelmRelease) (*yaml.Node, error) {
-	data, err := utils.ExecuteCommand("helm", "get", "values", release.Name, "-n", release.Namespace, "-o", "yaml")
+func (_helm) GetReleaseValues(ctx context.Context, release types.HelmRelease) (*yaml.Node, error) {
+	data, err := utils.ExecuteCommand(ctx, "helm", "get", "values", release.Name, "-n", release.Namespace, "-o", "yaml")
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +96,8 @@ func (_helm) GetReleaseValues(release types.HelmRelease) (*yaml.Node, error) {
 	return node, err
 }
 
-func (_helm) GetDefaultChartValues(chart types.HelmChart) (*yaml.Node, error) {
+// ShowValues returns the default values file of the chart as it is.
+func (_helm) ShowValues(ctx context.Context, chart types.HelmChart) ([]byte, error) {
 	args := []string{
 		"show",
 		"values",
@@ -103,21 +106,11 @@ func (_helm) GetDefaultChartValues(chart types.HelmChart) (*yaml.Node, error) {
 	if !chart.IsLocal && chart.Version != "" {
 		args = append(args, "--version", chart.Version)
 	}
-	data, err := utils.ExecuteCommand("helm", args...)
-	if err != nil {
-		return nil, err
-	}
-
-	node, err := utils.ParseYaml(data)
-	if err != nil {
-		return nil, err
-	}
-
-	return node, err
+	return utils.ExecuteCommandStdinOutput(ctx, nil, "helm", args...)
 }
 
-func (_helm) GetDeployedReleaseValues(release types.HelmRelease) (*yaml.Node, error) {
-	data, err := utils.ExecuteCommand("helm", "get", "values", release.Name, "-n", release.Namespace, "-o", "yaml", "--all")
+func (_helm) GetDeployedReleaseValues(ctx context.Context, release types.HelmRelease) (*yaml.Node, error) {
+	data, err := utils.ExecuteCommand(ctx, "helm", "get", "values", release.Name, "-n", release.Namespace, "-o", "yaml", "--all")
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +123,7 @@ func (_helm) GetDeployedReleaseValues(release types.HelmRelease) (*yaml.Node, er
 	return node, err
 }
 
-func (_helm) UpgradeRelease(release types.ManifestRelease, chart types.HelmChart, values []byte, dryRun bool, debug bool) ([]byte, error) {
+func (_helm) UpgradeRelease(ctx context.Context, release types.ManifestRelease, chart types.HelmChart, values []byte, dryRun bool, debug bool) ([]byte, error) {
 	args := []string{
 		"upgrade",
 		"--install",
@@ -153,10 +146,28 @@ func (_helm) UpgradeRelease(release types.ManifestRelease, chart types.HelmChart
 		args = append(args, "--debug")
 	}
 
-	return utils.ExecuteCommandStdin(bytes.NewReader(values), "helm", args...)
+	return utils.ExecuteCommandStdin(ctx, bytes.NewReader(values), "helm", args...)
 }
 
-func (_helm) UninstallRelease(release types.ManifestRelease, dryRun bool) ([]byte, error) {
+// Template renders the manifests helm would install for the release.
+func (_helm) Template(ctx context.Context, release types.ManifestRelease, chart types.HelmChart, values []byte) ([]byte, error) {
+	args := []string{
+		"template",
+		"--namespace",
+		release.Namespace,
+		release.Name,
+		chart.HelmName(),
+		"-f",
+		"-",
+	}
+	if !chart.IsLocal && chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+
+	return utils.ExecuteCommandStdinOutput(ctx, bytes.NewReader(values), "helm", args...)
+}
+
+func (_helm) UninstallRelease(ctx context.Context, release types.ManifestRelease, dryRun bool) ([]byte, error) {
 	args := []string{
 		"uninstall",
 		"--namespace",
@@ -168,9 +179,66 @@ func (_helm) UninstallRelease(release types.ManifestRelease, dryRun bool) ([]byt
 		args = append(args, "--dry-run")
 	}
 
-	return utils.ExecuteCommand("helm", args...)
+	return utils.ExecuteCommand(ctx, "helm", args...)
+}
+
+func (_helm) History(ctx context.Context, release types.ManifestRelease) ([]types.HelmReleaseRevision, error) {
+	resp, err := utils.ExecuteCommand(ctx, "helm", "history", release.Name, "--namespace", release.Namespace, "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []types.HelmReleaseRevision
+	if err = json.Unmarshal(resp, &revisions); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (_helm) Rollback(ctx context.Context, release types.ManifestRelease, revision int, dryRun bool) ([]byte, error) {
+	args := []string{
+		"rollback",
+		"--namespace",
+		release.Namespace,
+		release.Name,
+		strconv.Itoa(revision),
+	}
+
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+
+	return utils.ExecuteCommand(ctx, "helm", args...)
+}
+
+func (_helm) RemoveRepo(ctx context.Context, repo types.HelmRepo) ([]byte, error) {
+	return utils.ExecuteCommand(ctx, "helm", "repo", "remove", repo.Name)
+}
+
+// PullChart downloads the chart archive into dest, verifying its provenance file against keyring if one is given.
+func (_helm) PullChart(ctx context.Context, chart types.HelmChart, dest string, keyring string) ([]byte, error) {
+	args := []string{
+		"pull",
+		chart.HelmName(),
+		"--destination",
+		dest,
+	}
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+	if keyring != "" {
+		args = append(args, "--verify", "--keyring", keyring)
+	}
+
+	return utils.ExecuteCommand(ctx, "helm", args...)
+}
+
+// VerifyChart checks the provenance file next to a chart archive against keyring.
+func (_helm) VerifyChart(ctx context.Context, archive string, keyring string) ([]byte, error) {
+	return utils.ExecuteCommand(ctx, "helm", "verify", archive, "--keyring", keyring)
 }
 
-func (_helm) RemoveRepo(repo types.HelmRepo) ([]byte, error) {
-	return utils.ExecuteCommand("helm", "repo", "remove", repo.Name)
+func (_helm) BuildDependencies(ctx context.Context, dir string) ([]byte, error) {
+	return utils.ExecuteCommand(ctx, "helm", "dependency", "build", dir)
 }
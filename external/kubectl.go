@@ -2,6 +2,7 @@ package external
 
 import (
 	"bytes"
+	"context"
 	"strings"
 
 	"github.com/seventv/helm-manager/v2/utils"
@@ -11,8 +12,8 @@ type _kubectl struct{}
 
 var Kubectl = _kubectl{}
 
-func (_kubectl) GetNamespaces() ([]string, error) {
-	data, err := utils.ExecuteCommand("kubectl", "get", "ns", "-o", "jsonpath={.items[*].metadata.name}")
+func (_kubectl) GetNamespaces(ctx context.Context) ([]string, error) {
+	data, err := utils.ExecuteCommand(ctx, "kubectl", "get", "ns", "-o", "jsonpath={.items[*].metadata.name}")
 	if err != nil {
 		return nil, err
 	}
@@ -20,7 +21,7 @@ func (_kubectl) GetNamespaces() ([]string, error) {
 	return strings.Split(string(data), " "), nil
 }
 
-func (_kubectl) Deploy(values []byte, namespace string, useCreate bool, dryRun bool) ([]byte, error) {
+func (_kubectl) Deploy(ctx context.Context, values []byte, namespace string, useCreate bool, dryRun bool) ([]byte, error) {
 	args := []string{
 		"apply",
 		"-f",
@@ -39,10 +40,10 @@ func (_kubectl) Deploy(values []byte, namespace string, useCreate bool, dryRun b
 		args = append(args, "--create")
 	}
 
-	return utils.ExecuteCommandStdin(bytes.NewReader(values), "kubectl", args...)
+	return utils.ExecuteCommandStdin(ctx, bytes.NewReader(values), "kubectl", args...)
 }
 
-func (_kubectl) Delete(values []byte, namespace string, dryRun bool) ([]byte, error) {
+func (_kubectl) Delete(ctx context.Context, values []byte, namespace string, dryRun bool) ([]byte, error) {
 	args := []string{
 		"delete",
 		"-f",
@@ -57,14 +58,48 @@ func (_kubectl) Delete(values []byte, namespace string, dryRun bool) ([]byte, er
 		args = append(args, "--dry-run")
 	}
 
-	return utils.ExecuteCommandStdin(bytes.NewReader(values), "kubectl", args...)
+	return utils.ExecuteCommandStdin(ctx, bytes.NewReader(values), "kubectl", args...)
 }
 
-func (_kubectl) GetCurrentContext() (string, error) {
-	resp, err := utils.ExecuteCommand("kubectl", "config", "current-context")
+func (_kubectl) GetCurrentContext(ctx context.Context) (string, error) {
+	resp, err := utils.ExecuteCommand(ctx, "kubectl", "config", "current-context")
 	if err != nil {
 		return "", err
 	}
 
 	return strings.TrimSpace(string(resp)), nil
 }
+
+// GetResources returns the json output of kubectl get, name and selector are optional.
+func (_kubectl) GetResources(ctx context.Context, kind string, namespace string, name string, selector string) ([]byte, error) {
+	args := []string{"get", kind}
+
+	if name != "" {
+		args = append(args, name)
+	}
+
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+
+	args = append(args, "-o", "json")
+
+	return utils.ExecuteCommand(ctx, "kubectl", args...)
+}
+
+// GetObjects returns the json output of kubectl get for kind/name references, objects which do not exist are left out.
+func (_kubectl) GetObjects(ctx context.Context, namespace string, refs ...string) ([]byte, error) {
+	args := append([]string{"get"}, refs...)
+
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+
+	args = append(args, "--ignore-not-found", "-o", "json")
+
+	return utils.ExecuteCommand(ctx, "kubectl", args...)
+}
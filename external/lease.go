@@ -0,0 +1,126 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/seventv/helm-manager/v2/types"
+	"github.com/seventv/helm-manager/v2/utils"
+)
+
+// leaseTimeLayout is the MicroTime format the api server uses for the times of a Lease.
+const leaseTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+const leaseManifestAnnotation = "helm-manager/manifest"
+
+type kubeLease struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name            string            `json:"name"`
+		Namespace       string            `json:"namespace"`
+		ResourceVersion string            `json:"resourceVersion,omitempty"`
+		Annotations     map[string]string `json:"annotations,omitempty"`
+	} `json:"metadata"`
+	Spec struct {
+		HolderIdentity       string `json:"holderIdentity"`
+		LeaseDurationSeconds int64  `json:"leaseDurationSeconds"`
+		AcquireTime          string `json:"acquireTime"`
+		RenewTime            string `json:"renewTime"`
+	} `json:"spec"`
+}
+
+// KubeLeaseBackend stores the cluster lock in a coordination.k8s.io Lease, using the resourceVersion to detect concurrent writes.
+type KubeLeaseBackend struct {
+	Name      string
+	Namespace string
+}
+
+func (b KubeLeaseBackend) encode(lease types.LeaseRecord, version string) []byte {
+	obj := kubeLease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+	}
+	obj.Metadata.Name = b.Name
+	obj.Metadata.Namespace = b.Namespace
+	obj.Metadata.ResourceVersion = version
+	obj.Metadata.Annotations = map[string]string{leaseManifestAnnotation: lease.Manifest}
+	obj.Spec.HolderIdentity = lease.Holder
+	obj.Spec.LeaseDurationSeconds = int64(lease.TTL / time.Second)
+	obj.Spec.AcquireTime = lease.Acquired.UTC().Format(leaseTimeLayout)
+	obj.Spec.RenewTime = lease.Renewed.UTC().Format(leaseTimeLayout)
+
+	data, _ := json.Marshal(obj)
+	return data
+}
+
+// leaseErr turns conflicts reported by kubectl into types.ErrLeaseConflict.
+func leaseErr(err error, resp []byte) error {
+	if err == nil {
+		return nil
+	}
+
+	if out := string(resp); strings.Contains(out, "AlreadyExists") || strings.Contains(out, "Conflict") || strings.Contains(out, "the object has been modified") || strings.Contains(out, "NotFound") {
+		return types.ErrLeaseConflict
+	}
+
+	return fmt.Errorf("%v\n%s", err, resp)
+}
+
+func (b KubeLeaseBackend) Get(ctx context.Context) (types.LeaseRecord, bool, error) {
+	resp, err := utils.ExecuteCommand(ctx, "kubectl", "get", "lease", b.Name, "-n", b.Namespace, "--ignore-not-found", "-o", "json")
+	if err != nil {
+		return types.LeaseRecord{}, false, fmt.Errorf("%v\n%s", err, resp)
+	}
+
+	if len(bytes.TrimSpace(resp)) == 0 {
+		return types.LeaseRecord{}, false, nil
+	}
+
+	obj := kubeLease{}
+	if err := json.Unmarshal(resp, &obj); err != nil {
+		return types.LeaseRecord{}, false, err
+	}
+
+	// leases with missing or unreadable times count as expired
+	acquired, _ := time.Parse(time.RFC3339Nano, obj.Spec.AcquireTime)
+	renewed, _ := time.Parse(time.RFC3339Nano, obj.Spec.RenewTime)
+
+	return types.LeaseRecord{
+		Holder:   obj.Spec.HolderIdentity,
+		Manifest: obj.Metadata.Annotations[leaseManifestAnnotation],
+		Acquired: acquired,
+		Renewed:  renewed,
+		TTL:      time.Duration(obj.Spec.LeaseDurationSeconds) * time.Second,
+		Version:  obj.Metadata.ResourceVersion,
+	}, true, nil
+}
+
+func (b KubeLeaseBackend) Create(ctx context.Context, lease types.LeaseRecord) error {
+	resp, err := utils.ExecuteCommandStdin(ctx, bytes.NewReader(b.encode(lease, "")), "kubectl", "create", "-f", "-")
+	return leaseErr(err, resp)
+}
+
+func (b KubeLeaseBackend) Update(ctx context.Context, old types.LeaseRecord, lease types.LeaseRecord) error {
+	resp, err := utils.ExecuteCommandStdin(ctx, bytes.NewReader(b.encode(lease, old.Version)), "kubectl", "replace", "-f", "-")
+	return leaseErr(err, resp)
+}
+
+// Delete removes the lease only if it was not written since it was read, the api server checks the resourceVersion precondition.
+func (b KubeLeaseBackend) Delete(ctx context.Context, lease types.LeaseRecord) error {
+	options, _ := json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "DeleteOptions",
+		"preconditions": map[string]string{
+			"resourceVersion": lease.Version,
+		},
+	})
+
+	uri := fmt.Sprintf("/apis/coordination.k8s.io/v1/namespaces/%s/leases/%s", b.Namespace, b.Name)
+	resp, err := utils.ExecuteCommandStdin(ctx, bytes.NewReader(options), "kubectl", "delete", "--raw", uri, "-f", "-")
+	return leaseErr(err, resp)
+}
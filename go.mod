@@ -3,22 +3,22 @@ module github.com/seventv/helm-manager/v2
 go 1.19
 
 require (
+	github.com/chzyer/readline v1.5.1
 	github.com/fatih/color v1.13.0
 	github.com/gosuri/uilive v0.0.4
 	github.com/jinzhu/copier v0.3.5
 	github.com/manifoldco/promptui v0.9.0
 	github.com/spf13/cobra v1.5.0
 	go.uber.org/zap v1.23.0
+	golang.org/x/sys v0.0.0-20220919091848-fb04ddd9f9c8
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/chzyer/readline v1.5.1 // indirect
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.16 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	go.uber.org/atomic v1.10.0 // indirect
 	go.uber.org/multierr v1.8.0 // indirect
-	golang.org/x/sys v0.0.0-20220919091848-fb04ddd9f9c8 // indirect
 )
@@ -3,25 +3,69 @@ package logger
 import (
 	"fmt"
 	"io"
+	"os"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/gosuri/uilive"
 	"github.com/seventv/helm-manager/v2/constants"
+	"github.com/seventv/helm-manager/v2/redact"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 type writer struct {
+	mtx sync.Mutex
 	out *uilive.Writer
 }
 
 var Out io.Writer
+
+var exitHooks = struct {
+	mtx   sync.Mutex
+	hooks []func()
+}{}
+
+// OnExit registers fn to run before the process exits through Fatal, eg. to release locks held in the cluster.
+func OnExit(fn func()) {
+	exitHooks.mtx.Lock()
+	defer exitHooks.mtx.Unlock()
+
+	exitHooks.hooks = append(exitHooks.hooks, fn)
+}
+
+// RunExitHooks runs the functions registered with OnExit, each of them only runs once.
+func RunExitHooks() {
+	exitHooks.mtx.Lock()
+	hooks := exitHooks.hooks
+	exitHooks.hooks = nil
+	exitHooks.mtx.Unlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
+type exitHook struct{}
+
+func (exitHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	RunExitHooks()
+	os.Exit(1)
+}
+
 var previousLine = struct {
 	Data       []byte
 	WasRewrite bool
 }{}
 
 func LoggerRewrite() {
+	if w, ok := Out.(*writer); ok {
+		w.mtx.Lock()
+		defer w.mtx.Unlock()
+	}
+
 	if len(previousLine.Data) != 0 && previousLine.WasRewrite {
 		_, _ = Out.Write(previousLine.Data)
 		previousLine.WasRewrite = false
@@ -29,6 +73,8 @@ func LoggerRewrite() {
 }
 
 func (w *writer) Write(msg []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
 	defer w.out.Flush()
 
 	if len(msg) > 2 && msg[len(msg)-2] == '\r' {
@@ -48,10 +94,53 @@ func (w *writer) Write(msg []byte) (int, error) {
 }
 
 func init() {
-	setupLogger(false)
+	if err := Setup(Options{}); err != nil {
+		panic(err)
+	}
+}
+
+const (
+	VerbosityInfo    = 0 // only info messages and above
+	VerbosityDebug   = 1 // -v, debug messages and the external commands which ran, with their output
+	VerbosityVerbose = 2 // -vv, debug messages with their time and caller
+)
+
+type Options struct {
+	Verbosity int
+	// File gets every message as json, including the debug messages, regardless of the verbosity.
+	File string
+}
+
+var options Options
+
+// logFile is shared by every logger, Redirect only swaps the console.
+var logFile zapcore.WriteSyncer
+
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+// plainCore strips the terminal colors from messages, for the log file.
+type plainCore struct {
+	zapcore.Core
+}
+
+func (c plainCore) With(fields []zapcore.Field) zapcore.Core {
+	return plainCore{c.Core.With(fields)}
+}
+
+func (c plainCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
 }
 
-func setupLogger(debug bool) {
+func (c plainCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = ansiRegex.ReplaceAllString(ent.Message, "")
+	return c.Core.Write(ent, fields)
+}
+
+func newLogger(out io.Writer) *zap.Logger {
 	cfg := zap.NewProductionConfig()
 
 	cfg.Encoding = "console"
@@ -62,14 +151,57 @@ func setupLogger(debug bool) {
 	cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 
 	lvl := zap.NewAtomicLevelAt(zapcore.InfoLevel)
-	if debug {
+	if options.Verbosity >= VerbosityDebug {
 		lvl = zap.NewAtomicLevelAt(zapcore.DebugLevel)
-	} else {
+	}
+
+	if options.Verbosity < VerbosityVerbose {
 		cfg.EncoderConfig.CallerKey = ""
 		cfg.EncoderConfig.LevelKey = ""
 		cfg.EncoderConfig.TimeKey = ""
 	}
 
+	core := zapcore.NewCore(
+		zapcore.NewConsoleEncoder(cfg.EncoderConfig),
+		zapcore.Lock(zapcore.AddSync(redact.Writer(out))),
+		lvl,
+	)
+
+	if logFile != nil {
+		fileCfg := zap.NewProductionEncoderConfig()
+		fileCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		fileCfg.StacktraceKey = ""
+
+		core = zapcore.NewTee(core, plainCore{zapcore.NewCore(
+			zapcore.NewJSONEncoder(fileCfg),
+			logFile,
+			zapcore.DebugLevel,
+		)})
+	}
+
+	return zap.New(core, zap.WithFatalHook(exitHook{}))
+}
+
+// Setup configures the console verbosity and the log file, it can be called again once the flags are parsed.
+func Setup(opts Options) error {
+	if opts.File != options.File {
+		if f, ok := logFile.(*os.File); ok {
+			_ = f.Close()
+		}
+		logFile = nil
+
+		if opts.File != "" {
+			f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to open log file: %w", err)
+			}
+
+			logFile = zapcore.Lock(redact.File{File: f})
+		}
+	}
+
+	options = opts
+
 	Out = color.Output
 	if constants.InTerm() {
 		uilive.Out = Out
@@ -77,17 +209,43 @@ func setupLogger(debug bool) {
 		Out = &writer{out: out}
 	}
 
-	logger := zap.New(zapcore.NewCore(
-		zapcore.NewConsoleEncoder(cfg.EncoderConfig),
-		zapcore.AddSync(Out),
-		lvl,
-	))
+	zap.ReplaceGlobals(newLogger(Out))
+	return nil
+}
+
+// Verbosity is the console verbosity set with Setup.
+func Verbosity() int {
+	return options.Verbosity
+}
 
-	zap.ReplaceGlobals(logger)
+// LogsCommandOutput reports whether the output of external commands ends up anywhere, on the console at VerbosityDebug or in the log file.
+func LogsCommandOutput() bool {
+	return options.Verbosity >= VerbosityDebug || logFile != nil
 }
 
-func SetDebug(debug bool) {
-	setupLogger(debug)
+// Redirect sends the log output to w until the returned function is called, eg. while a full screen ui owns the terminal.
+func Redirect(w io.Writer) func() {
+	zap.ReplaceGlobals(newLogger(w))
+
+	return func() {
+		zap.ReplaceGlobals(newLogger(Out))
+	}
+}
+
+// Command records an external command which finished, with how long it took and its exit code.
+// Its output is nil unless LogsCommandOutput, since it is often large.
+func Command(command string, duration time.Duration, exitCode int, output []byte) {
+	fields := []zap.Field{
+		zap.String("command", command),
+		zap.Duration("duration", duration),
+		zap.Int("exit_code", exitCode),
+	}
+
+	if output != nil {
+		fields = append(fields, zap.ByteString("output", output))
+	}
+
+	zap.L().Debug(fmt.Sprintf("%s %s", color.New(color.Bold, color.FgBlack).Sprint("$"), color.MagentaString(command)), fields...)
 }
 
 func Debug(args ...any) {
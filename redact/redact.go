@@ -0,0 +1,156 @@
+// Package redact masks secrets in everything helm-manager prints or records.
+// The values of the env variables substituted into releases and singles are registered as they are read,
+// anything else which is secret, such as decrypted values, has to be registered with Add.
+package redact
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Text replaces secrets.
+const Text = "***"
+
+// minSecretLength keeps short values like "1" or "true" from masking half of every line.
+const minSecretLength = 4
+
+var secrets = struct {
+	mtx      sync.RWMutex
+	disabled bool
+	values   map[string]bool
+	replacer *strings.Replacer
+}{
+	values: map[string]bool{},
+}
+
+// Add registers secret values which are masked from now on, along with their json escaped and base64 encoded forms.
+func Add(values ...string) {
+	secrets.mtx.Lock()
+	defer secrets.mtx.Unlock()
+
+	for _, v := range values {
+		if len(v) >= minSecretLength {
+			secrets.values[v] = true
+		}
+	}
+
+	forms := map[string]bool{}
+	for v := range secrets.values {
+		forms[v] = true
+
+		// json output escapes quotes and backslashes
+		if escaped, err := json.Marshal(v); err == nil {
+			forms[string(escaped[1:len(escaped)-1])] = true
+		}
+
+		// helm renders the data of kubernetes secrets base64 encoded
+		forms[base64.StdEncoding.EncodeToString([]byte(v))] = true
+	}
+
+	// longer secrets first, so one containing another is masked as a whole
+	sorted := make([]string, 0, len(forms))
+	for v := range forms {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+
+		return sorted[i] < sorted[j]
+	})
+
+	pairs := make([]string, 0, len(sorted)*2)
+	for _, v := range sorted {
+		pairs = append(pairs, v, Text)
+	}
+
+	secrets.replacer = strings.NewReplacer(pairs...)
+}
+
+// SetEnabled turns masking on or off, it is on unless --show-secrets is passed.
+func SetEnabled(enabled bool) {
+	secrets.mtx.Lock()
+	defer secrets.mtx.Unlock()
+
+	secrets.disabled = !enabled
+}
+
+// Enabled reports if secrets are masked.
+func Enabled() bool {
+	secrets.mtx.RLock()
+	defer secrets.mtx.RUnlock()
+
+	return !secrets.disabled
+}
+
+// String masks the registered secrets in s.
+func String(s string) string {
+	secrets.mtx.RLock()
+	defer secrets.mtx.RUnlock()
+
+	if secrets.replacer == nil || secrets.disabled {
+		return s
+	}
+
+	return secrets.replacer.Replace(s)
+}
+
+// Error masks the registered secrets in the message of err, errors.Is and errors.As still see the original error.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := String(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+
+	return redactedError{msg: msg, err: err}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e redactedError) Error() string {
+	return e.msg
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+type writer struct {
+	w io.Writer
+}
+
+// Writer masks the registered secrets in everything written to w.
+// Secrets are only found within a single write, which is how loggers and encoders write.
+func Writer(w io.Writer) io.Writer {
+	return writer{w}
+}
+
+func (r writer) Write(p []byte) (int, error) {
+	if _, err := r.w.Write([]byte(String(string(p)))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// File is Writer for files which have to be synced, such as the log file.
+type File struct {
+	*os.File
+}
+
+func (f File) Write(p []byte) (int, error) {
+	return writer{f.File}.Write(p)
+}
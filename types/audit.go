@@ -0,0 +1,42 @@
+package types
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionAdd       AuditAction = "add"       // an entry was added to the manifest
+	AuditActionUpdate    AuditAction = "update"    // a release was moved to another chart version in the manifest
+	AuditActionImport    AuditAction = "import"    // an entry was imported into the manifest
+	AuditActionRemove    AuditAction = "remove"    // an entry was removed from the manifest
+	AuditActionDeploy    AuditAction = "deploy"    // a release or single was applied to the cluster
+	AuditActionUninstall AuditAction = "uninstall" // a release or single was deleted from the cluster
+	AuditActionRollback  AuditAction = "rollback"  // a release was rolled back to an older helm revision
+	AuditActionUnlock    AuditAction = "unlock"    // the cluster lock was released by hand
+)
+
+type AuditResult string
+
+const (
+	AuditResultSuccess   AuditResult = "success"
+	AuditResultFailure   AuditResult = "failure"
+	AuditResultUnchanged AuditResult = "unchanged" // the cluster already matched, nothing was done
+)
+
+type AuditEntry struct {
+	Time        time.Time   `json:"time"`
+	User        string      `json:"user"`
+	Command     string      `json:"command"`
+	Action      AuditAction `json:"action"`
+	Kind        string      `json:"kind"` // release, single, repo, env, local-chart, namespace or lease
+	Name        string      `json:"name"`
+	Namespace   string      `json:"namespace,omitempty"`
+	OldVersion  string      `json:"old_version,omitempty"`
+	NewVersion  string      `json:"new_version,omitempty"`
+	ValuesHash  string      `json:"values_hash,omitempty"`
+	GitCommit   string      `json:"git_commit,omitempty"`
+	GitDirty    bool        `json:"git_dirty,omitempty"`
+	KubeContext string      `json:"kube_context,omitempty"`
+	Result      AuditResult `json:"result"`
+	Error       string      `json:"error,omitempty"`
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+func (s Severity) Colored() string {
+	switch s {
+	case SeverityError:
+		return color.RedString(string(s))
+	case SeverityWarning:
+		return color.YellowString(string(s))
+	default:
+		return color.CyanString(string(s))
+	}
+}
+
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Field    string   `json:"field,omitempty"` // Path of the offending value inside the file, eg. releases[2].chart.repo
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) Location() string {
+	switch {
+	case d.File == "":
+		return d.Field
+	case d.Line == 0:
+		return d.File
+	case d.Column == 0:
+		return fmt.Sprintf("%s:%d", d.File, d.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Location(), d.Severity, d.Message)
+}
+
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
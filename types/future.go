@@ -1,51 +1,207 @@
 package types
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
 
+// future runs its function at most once, concurrent callers of Get wait for the same call instead of starting their own.
 type future[T any] struct {
-	f      func() (T, error)
+	f    func() (T, error)
+	mtx  sync.Mutex
+	call *futureCall[T]
+}
+
+type futureCall[T any] struct {
+	done   chan struct{}
 	result T
 	err    error
-	stored bool
 }
 
 type Future[T any] interface {
 	Get() (T, error)
+	// GetContext is Get which stops waiting when ctx is done, the call itself keeps running for other callers.
+	GetContext(ctx context.Context) (T, error)
 	GetOrPanic() T
+	// Prefetch starts the call in the background if it has not started yet.
+	Prefetch()
+	// Reset waits for a running call and forgets its result, so the next Get calls the function again.
 	Reset()
 	String() string
 }
 
+// Prefetcher is any Future, regardless of its type.
+type Prefetcher interface {
+	Prefetch()
+}
+
+// Prefetch starts all futures in the background, so work which does not depend on each other runs in parallel.
+func Prefetch(futures ...Prefetcher) {
+	for _, f := range futures {
+		f.Prefetch()
+	}
+}
+
+// start returns the current call, and whether the caller created it and has to run it.
+func (f *future[T]) start() (*futureCall[T], bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.call != nil {
+		return f.call, false
+	}
+
+	f.call = &futureCall[T]{done: make(chan struct{})}
+	return f.call, true
+}
+
+func (f *future[T]) run(call *futureCall[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("future panicked: %v", r)
+			close(call.done)
+			panic(r)
+		}
+
+		close(call.done)
+	}()
+
+	if f.f != nil {
+		call.result, call.err = f.f()
+	}
+}
+
 func (f *future[T]) Get() (T, error) {
-	if !f.stored && f.f != nil {
-		f.result, f.err = f.f()
-		f.stored = true
+	call, owner := f.start()
+	if owner {
+		f.run(call)
 	}
 
-	return f.result, f.err
+	<-call.done
+	return call.result, call.err
 }
 
-func (f *future[T]) GetOrPanic() T {
-	if !f.stored && f.f != nil {
-		f.result, f.err = f.f()
-		f.stored = true
+func (f *future[T]) GetContext(ctx context.Context) (t T, err error) {
+	call, owner := f.start()
+	if owner {
+		go f.run(call)
+	}
+
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		return t, ctx.Err()
 	}
+}
 
-	if f.err != nil {
-		panic(f.err)
+func (f *future[T]) GetOrPanic() T {
+	result, err := f.Get()
+	if err != nil {
+		panic(err)
 	}
 
-	return f.result
+	return result
+}
+
+func (f *future[T]) Prefetch() {
+	if call, owner := f.start(); owner {
+		go f.run(call)
+	}
 }
 
 func (f *future[T]) Reset() {
-	f.stored = false
+	f.mtx.Lock()
+	call := f.call
+	f.mtx.Unlock()
+
+	if call == nil {
+		return
+	}
+
+	<-call.done
+
+	f.mtx.Lock()
+	if f.call == call {
+		f.call = nil
+	}
+	f.mtx.Unlock()
 }
 
 func (f *future[T]) String() string {
 	return fmt.Sprint(f.GetOrPanic())
 }
 
+// FutureErrors are the errors of the futures given to All, in the same order.
+type FutureErrors []error
+
+func (e FutureErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func (e FutureErrors) Unwrap() []error {
+	return e
+}
+
+// All resolves the futures in parallel, the results keep the order of the futures.
+// It fails with FutureErrors holding every error, not only the first one.
+func All[T any](futures ...Future[T]) Future[[]T] {
+	return &future[[]T]{
+		f: func() ([]T, error) {
+			Prefetch(FuturePrefetchers(futures)...)
+
+			results := make([]T, len(futures))
+			var errs FutureErrors
+			for i, f := range futures {
+				result, err := f.Get()
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+
+				results[i] = result
+			}
+
+			if len(errs) != 0 {
+				return results, errs
+			}
+
+			return results, nil
+		},
+	}
+}
+
+// Map transforms the result of a future, errors of the future are returned as they are.
+func Map[T any, U any](f Future[T], fn func(T) (U, error)) Future[U] {
+	return &future[U]{
+		f: func() (u U, err error) {
+			t, err := f.Get()
+			if err != nil {
+				return u, err
+			}
+
+			return fn(t)
+		},
+	}
+}
+
+// FuturePrefetchers lets a slice of futures be passed to Prefetch.
+func FuturePrefetchers[T any](futures []Future[T]) []Prefetcher {
+	prefetchers := make([]Prefetcher, len(futures))
+	for i, f := range futures {
+		prefetchers[i] = f
+	}
+
+	return prefetchers
+}
+
 func FutureFrom[T any](a T) Future[T] {
 	return &future[T]{
 		f: func() (T, error) {
@@ -0,0 +1,105 @@
+package types
+
+import "gopkg.in/yaml.v3"
+
+// The types below only cover the fields of Argo CD and Flux objects which helm-manager can translate.
+
+type KubeMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+type ArgoApplication struct {
+	APIVersion string              `yaml:"apiVersion"`
+	Kind       string              `yaml:"kind"`
+	Metadata   KubeMetadata        `yaml:"metadata"`
+	Spec       ArgoApplicationSpec `yaml:"spec"`
+}
+
+type ArgoApplicationSpec struct {
+	Project     string                     `yaml:"project"`
+	Source      ArgoApplicationSource      `yaml:"source"`
+	Sources     []ArgoApplicationSource    `yaml:"sources,omitempty"`
+	Destination ArgoApplicationDestination `yaml:"destination"`
+}
+
+type ArgoApplicationSource struct {
+	RepoURL        string               `yaml:"repoURL"`
+	Chart          string               `yaml:"chart,omitempty"`
+	Path           string               `yaml:"path,omitempty"`
+	TargetRevision string               `yaml:"targetRevision"`
+	Helm           *ArgoApplicationHelm `yaml:"helm,omitempty"`
+}
+
+type ArgoApplicationHelm struct {
+	ReleaseName  string      `yaml:"releaseName,omitempty"`
+	Values       string      `yaml:"values,omitempty"`
+	ValuesObject *yaml.Node  `yaml:"valuesObject,omitempty"`
+	ValueFiles   []string    `yaml:"valueFiles,omitempty"`
+	Parameters   []yaml.Node `yaml:"parameters,omitempty"`
+}
+
+type ArgoApplicationDestination struct {
+	Server    string `yaml:"server,omitempty"`
+	Name      string `yaml:"name,omitempty"`
+	Namespace string `yaml:"namespace"`
+}
+
+type FluxHelmRepository struct {
+	APIVersion string                 `yaml:"apiVersion"`
+	Kind       string                 `yaml:"kind"`
+	Metadata   KubeMetadata           `yaml:"metadata"`
+	Spec       FluxHelmRepositorySpec `yaml:"spec"`
+}
+
+type FluxHelmRepositorySpec struct {
+	Interval string `yaml:"interval"`
+	URL      string `yaml:"url"`
+	Type     string `yaml:"type,omitempty"`
+}
+
+type FluxHelmRelease struct {
+	APIVersion string              `yaml:"apiVersion"`
+	Kind       string              `yaml:"kind"`
+	Metadata   KubeMetadata        `yaml:"metadata"`
+	Spec       FluxHelmReleaseSpec `yaml:"spec"`
+}
+
+type FluxHelmReleaseSpec struct {
+	Interval        string                `yaml:"interval"`
+	ReleaseName     string                `yaml:"releaseName,omitempty"`
+	TargetNamespace string                `yaml:"targetNamespace,omitempty"`
+	Chart           FluxHelmChartTemplate `yaml:"chart"`
+	DependsOn       []FluxDependency      `yaml:"dependsOn,omitempty"`
+	Values          *yaml.Node            `yaml:"values,omitempty"`
+	ValuesFrom      []FluxValuesReference `yaml:"valuesFrom,omitempty"`
+}
+
+type FluxHelmChartTemplate struct {
+	Spec FluxHelmChartSpec `yaml:"spec"`
+}
+
+type FluxHelmChartSpec struct {
+	Chart     string        `yaml:"chart"`
+	Version   string        `yaml:"version,omitempty"`
+	SourceRef FluxSourceRef `yaml:"sourceRef"`
+}
+
+type FluxSourceRef struct {
+	Kind      string `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+type FluxDependency struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+type FluxValuesReference struct {
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+	ValuesKey  string `yaml:"valuesKey,omitempty"`
+	TargetPath string `yaml:"targetPath,omitempty"`
+	Optional   bool   `yaml:"optional,omitempty"`
+}
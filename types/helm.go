@@ -131,6 +131,19 @@ type HelmRelease struct {
 	AppVersion   string `json:"app_version"`
 }
 
+type HelmReleaseRevision struct {
+	Revision     int    `json:"revision"`
+	Updated      string `json:"updated"`
+	Status       string `json:"status"`
+	ChartVersion string `json:"chart"`
+	AppVersion   string `json:"app_version"`
+	Description  string `json:"description"`
+}
+
+func (h HelmReleaseRevision) Version() string {
+	return HelmRelease{ChartVersion: h.ChartVersion}.Version()
+}
+
 func (h HelmRelease) String() string {
 	return h.Name
 }
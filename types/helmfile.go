@@ -0,0 +1,64 @@
+package types
+
+import (
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Helmfile is the subset of a helmfile.yaml that can be imported, anything else ends up in Extra so it can be reported.
+type Helmfile struct {
+	Repositories []HelmfileRepository           `yaml:"repositories"`
+	Releases     []HelmfileRelease              `yaml:"releases"`
+	Environments map[string]HelmfileEnvironment `yaml:"environments"`
+
+	Extra map[string]yaml.Node `yaml:",inline"`
+}
+
+type HelmfileRepository struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	OCI  bool   `yaml:"oci"`
+
+	Extra map[string]yaml.Node `yaml:",inline"`
+}
+
+type HelmfileRelease struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace"`
+	Chart     string            `yaml:"chart"`
+	Version   string            `yaml:"version"`
+	Values    []yaml.Node       `yaml:"values"`
+	Set       []HelmfileSet     `yaml:"set"`
+	SetString []HelmfileSet     `yaml:"setString"`
+	Needs     []string          `yaml:"needs"`
+	Labels    map[string]string `yaml:"labels"`
+	Installed *bool             `yaml:"installed"`
+
+	Extra map[string]yaml.Node `yaml:",inline"`
+}
+
+type HelmfileSet struct {
+	Name   string      `yaml:"name"`
+	Value  yaml.Node   `yaml:"value"`
+	Values []yaml.Node `yaml:"values"`
+	File   string      `yaml:"file"`
+}
+
+type HelmfileEnvironment struct {
+	Values []yaml.Node `yaml:"values"`
+
+	Extra map[string]yaml.Node `yaml:",inline"`
+}
+
+// ExtraKeys returns the sorted keys of fields which are not understood by the importer.
+func ExtraKeys(extra map[string]yaml.Node) []string {
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
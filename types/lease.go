@@ -0,0 +1,47 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultLeaseName      = "helm-manager"
+	DefaultLeaseNamespace = "default"
+	DefaultLeaseTTL       = 15 * time.Minute
+)
+
+// ErrLeaseConflict is returned by a LeaseBackend when the lease changed between reading and writing it.
+var ErrLeaseConflict = errors.New("lease was changed by someone else")
+
+// LeaseRecord is the state of the cluster lock.
+type LeaseRecord struct {
+	Holder   string        // user@host:pid of the process holding the lease
+	Manifest string        // name of the manifest the holder is deploying
+	Acquired time.Time     // when the lease was taken
+	Renewed  time.Time     // when the holder last showed it is still alive
+	TTL      time.Duration // how long after Renewed the lease may be taken over
+	Version  string        // version of the stored lease, used by backends to detect concurrent writes
+}
+
+func (l LeaseRecord) Expires() time.Time {
+	return l.Renewed.Add(l.TTL)
+}
+
+func (l LeaseRecord) Expired(now time.Time) bool {
+	return !now.Before(l.Expires())
+}
+
+func (l LeaseRecord) String() string {
+	return fmt.Sprintf("%s (manifest %s, since %s, expires %s)", l.Holder, l.Manifest, l.Acquired.Local().Format("2006-01-02 15:04:05"), l.Expires().Local().Format("2006-01-02 15:04:05"))
+}
+
+// LeaseBackend stores the cluster lock. Create and Update must fail with ErrLeaseConflict instead of overwriting a lease they did not read.
+type LeaseBackend interface {
+	Get(ctx context.Context) (LeaseRecord, bool, error)
+	Create(ctx context.Context, lease LeaseRecord) error
+	Update(ctx context.Context, old LeaseRecord, lease LeaseRecord) error
+	Delete(ctx context.Context, lease LeaseRecord) error
+}
@@ -3,50 +3,219 @@ package types
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 var GlobalManifest = &Manifest{}
 
+const (
+	ManifestAPIVersionV1 = "helm-manager/v1" // manifests written before apiVersion existed
+	ManifestAPIVersionV2 = "helm-manager/v2"
+
+	ManifestAPIVersion = ManifestAPIVersionV2 // the version written by this build
+)
+
 type Manifest struct {
-	Name        string             `yaml:"name"`
-	Repos       []ManifestRepo     `yaml:"repos"`        // Helm repos
-	AllowedEnv  []SelectableString `yaml:"allowed_env"`  // Allowed environment variables
-	Releases    []ManifestRelease  `yaml:"releases"`     // Helm releases
-	Singles     []ManifestSingle   `yaml:"singles"`      // Single files
-	LocalCharts []SelectableString `yaml:"local_charts"` // Local charts
+	APIVersion  string              `yaml:"apiVersion"` // Schema version of the manifest
+	Name        string              `yaml:"name"`
+	Repos       []ManifestRepo      `yaml:"repos"`                // Helm repos
+	AllowedEnv  []SelectableString  `yaml:"allowed_env"`          // Allowed environment variables
+	Releases    []ManifestRelease   `yaml:"releases"`             // Helm releases
+	Singles     []ManifestSingle    `yaml:"singles"`              // Single files
+	LocalCharts []SelectableString  `yaml:"local_charts"`         // Local charts
+	Namespaces  []ManifestNamespace `yaml:"namespaces,omitempty"` // Namespaces releases and singles are installed in
+	Lease       ManifestLease       `yaml:"lease,omitempty"`      // Cluster lock taken before changing anything in the cluster
+	Timeouts    ManifestTimeouts    `yaml:"timeouts,omitempty"`   // How long helm, kubectl and git may take
+	Keyring     string              `yaml:"keyring,omitempty"`    // Keyring to verify chart provenance files against (optional)
+	Vendor      bool                `yaml:"vendor,omitempty"`     // Deploy charts from the archives in charts/ instead of the repos
 
-	Exists bool `yaml:"-"` // Whether the manifest exists
+	Exists           bool   `yaml:"-"` // Whether the manifest exists
+	SourceAPIVersion string `yaml:"-"` // The apiVersion the manifest was read as, before migrations
 }
 
-func (m Manifest) Validate() bool {
+// Validate checks the manifest for problems which do not need the filesystem or the cluster to detect.
+// The returned diagnostics only have the Field set, the caller is responsible for resolving it to a location.
+func (m Manifest) Validate() Diagnostics {
+	diags := Diagnostics{}
+	errorf := func(field string, format string, args ...any) {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Field:    field,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	checkName := func(field string, kind string, name string, allowEmpty bool) {
+		if name == "" && !allowEmpty {
+			errorf(field, "%s cannot be empty", kind)
+		} else if name != "" && !NameRegex.MatchString(name) {
+			errorf(field, "%s \"%s\" %s", kind, name, ErrInvalidName.Error())
+		}
+	}
+
 	repoMap := make(map[string]bool)
-	for _, repo := range m.Repos {
-		if repoMap[repo.Name] {
-			return false
+	for i, repo := range m.Repos {
+		field := fmt.Sprintf("repos[%d].name", i)
+		checkName(field, "repo name", repo.Name, false)
+		if repoMap[strings.ToLower(repo.Name)] {
+			errorf(field, "repo \"%s\" is defined more than once", repo.Name)
+		}
+
+		if !UrlRegex.MatchString(repo.URL) {
+			errorf(fmt.Sprintf("repos[%d].url", i), "repo url %s", ErrInvalidURL.Error())
 		}
 
-		repoMap[repo.Name] = true
+		repoMap[strings.ToLower(repo.Name)] = true
 	}
 
 	releaseMap := make(map[string]bool)
-	for _, release := range m.Releases {
-		if releaseMap[release.Name] {
-			return false
+	for i, release := range m.Releases {
+		field := fmt.Sprintf("releases[%d]", i)
+		checkName(field+".name", "release name", release.Name, false)
+		checkName(field+".namespace", "release namespace", release.Namespace, true)
+		if releaseMap[strings.ToLower(release.Name)] {
+			errorf(field+".name", "release \"%s\" is defined more than once", release.Name)
+		}
+
+		if release.Chart.Name == "" {
+			errorf(field+".chart.name", "release \"%s\" has no chart", release.Name)
+		}
+
+		if release.Chart.Version == "" {
+			errorf(field+".chart.version", "release \"%s\" has no chart version", release.Name)
 		}
 
-		releaseMap[release.Name] = true
+		if release.Chart.Repo != "" && !repoMap[strings.ToLower(release.Chart.Repo)] {
+			errorf(field+".chart.repo", "repo \"%s\" used by release \"%s\" is not in the manifest", release.Chart.Repo, release.Name)
+		}
+
+		validateHooks(field+".hooks", release.Hooks, errorf)
+		validateWaivers(field+".waivers", release.Name, release.Waivers, errorf)
+		validateLabels(field+".labels", release.Name, release.Labels, errorf)
+
+		if timeout, err := release.DeployTimeout(m.Timeouts); release.Timeout != "" && (err != nil || timeout <= 0) {
+			errorf(field+".timeout", "release \"%s\" timeout \"%s\" is not a valid duration", release.Name, release.Timeout)
+		}
+
+		releaseMap[strings.ToLower(release.Name)] = true
 	}
 
 	singleMap := make(map[string]bool)
-	for _, single := range m.Singles {
-		if singleMap[single.Name] {
-			return false
+	for i, single := range m.Singles {
+		field := fmt.Sprintf("singles[%d]", i)
+		checkName(field+".name", "single name", single.Name, false)
+		checkName(field+".namespace", "single namespace", single.Namespace, true)
+		if singleMap[strings.ToLower(single.Name)] {
+			errorf(field+".name", "single \"%s\" is defined more than once", single.Name)
+		}
+
+		validateHooks(field+".hooks", single.Hooks, errorf)
+		validateWaivers(field+".waivers", single.Name, single.Waivers, errorf)
+		validateLabels(field+".labels", single.Name, single.Labels, errorf)
+
+		singleMap[strings.ToLower(single.Name)] = true
+	}
+
+	for i, release := range m.Releases {
+		validateDependsOn(fmt.Sprintf("releases[%d].depends_on", i), ManifestEntry{Kind: EntryKindRelease, Release: release}, releaseMap, singleMap, errorf)
+	}
+
+	for i, single := range m.Singles {
+		validateDependsOn(fmt.Sprintf("singles[%d].depends_on", i), ManifestEntry{Kind: EntryKindSingle, Single: single}, releaseMap, singleMap, errorf)
+	}
+
+	if _, err := SortEntries(m.Entries()); err != nil {
+		errorf("", "%v", err)
+	}
+
+	namespaceMap := make(map[string]bool)
+	for i, ns := range m.Namespaces {
+		field := fmt.Sprintf("namespaces[%d]", i)
+		checkName(field+".name", "namespace name", ns.Name, false)
+		if namespaceMap[strings.ToLower(ns.Name)] {
+			errorf(field+".name", "namespace \"%s\" is defined more than once", ns.Name)
+		}
+
+		for key, value := range ns.Quota {
+			if strings.TrimSpace(value) == "" {
+				errorf(field+".quota."+key, "quota %s of namespace \"%s\" has no value", key, ns.Name)
+			}
+		}
+
+		namespaceMap[strings.ToLower(ns.Name)] = true
+	}
+
+	for _, op := range []TimeoutOperation{TimeoutQuery, TimeoutRepo, TimeoutDeploy} {
+		if timeout, err := m.Timeouts.Duration(op); err != nil || timeout <= 0 {
+			errorf("timeouts."+string(op), "%s timeout is not a valid duration", op)
+		}
+	}
+
+	checkName("lease.name", "lease name", m.Lease.Name, true)
+	checkName("lease.namespace", "lease namespace", m.Lease.Namespace, true)
+	if ttl, err := m.Lease.TTLDuration(); err != nil || ttl <= 0 {
+		errorf("lease.ttl", "lease ttl \"%s\" is not a valid duration", m.Lease.TTL)
+	}
+
+	if wait, err := m.Lease.WaitDuration(); err != nil || wait < 0 {
+		errorf("lease.wait", "lease wait \"%s\" is not a valid duration", m.Lease.Wait)
+	}
+
+	envMap := make(map[string]bool)
+	for i, env := range m.AllowedEnv {
+		field := fmt.Sprintf("allowed_env[%d]", i)
+		if !EnvRegex.MatchString(env.String()) {
+			errorf(field, "\"%s\" is not a valid env variable name", env)
+		} else if envMap[strings.ToUpper(env.String())] {
+			errorf(field, "env variable \"%s\" is allowed more than once", env)
 		}
 
-		singleMap[single.Name] = true
+		envMap[strings.ToUpper(env.String())] = true
 	}
 
-	return true
+	localMap := make(map[string]bool)
+	for i, chart := range m.LocalCharts {
+		if localMap[chart.String()] {
+			errorf(fmt.Sprintf("local_charts[%d]", i), "local chart \"%s\" is added more than once", chart)
+		}
+
+		localMap[chart.String()] = true
+	}
+
+	return diags
+}
+
+func validateDependsOn(field string, entry ManifestEntry, releases map[string]bool, singles map[string]bool, errorf func(field string, format string, args ...any)) {
+	for i, dep := range entry.DependsOn() {
+		kind, name := ParseDependency(dep)
+		depField := fmt.Sprintf("%s[%d]", field, i)
+
+		switch {
+		case entryKey(kind, name) == entry.Key():
+			errorf(depField, "%s cannot depend on itself", entry)
+		case kind == "" && strings.EqualFold(name, entry.Name()):
+			errorf(depField, "%s cannot depend on itself, use %s/%s or %s/%s to pick the release or single of the same name", entry, EntryKindRelease, name, EntryKindSingle, name)
+		case kind == EntryKindRelease && !releases[name], kind == EntryKindSingle && !singles[name]:
+			errorf(depField, "%s depends on %s %s which is not in the manifest", entry, kind, name)
+		case kind == "" && !releases[name] && !singles[name]:
+			errorf(depField, "%s depends on \"%s\" which is not a release or single in the manifest", entry, name)
+		}
+	}
+}
+
+func validateHooks(field string, hooks ManifestHooks, errorf func(field string, format string, args ...any)) {
+	for _, event := range []HookEvent{HookPreDeploy, HookPostDeploy, HookPreRemove, HookPostRemove, HookOnFailure} {
+		for i, hook := range hooks.ByEvent(event) {
+			hookField := fmt.Sprintf("%s.%s[%d]", field, event, i)
+			if strings.TrimSpace(hook.Command) == "" {
+				errorf(hookField+".command", "%s hook has no command", event)
+			}
+
+			if timeout, err := hook.TimeoutDuration(); err != nil || timeout <= 0 {
+				errorf(hookField+".timeout", "%s hook timeout \"%s\" is not a valid duration", event, hook.Timeout)
+			}
+		}
+	}
 }
 
 func (m Manifest) RepoByName(name string) ManifestRepo {
@@ -77,7 +246,33 @@ func (m Manifest) ReleaseIdxByName(name string) (ManifestRelease, int) {
 	return ManifestRelease{}, -1
 }
 
+func (m Manifest) NamespaceByName(name string) ManifestNamespace {
+	name = strings.ToLower(name)
+	for _, ns := range m.Namespaces {
+		if strings.ToLower(ns.Name) == name {
+			return ns
+		}
+	}
+
+	return ManifestNamespace{}
+}
+
+// NamespaceUsers returns the releases and singles installed in a namespace.
+func (m Manifest) NamespaceUsers(name string) []ManifestEntry {
+	name = strings.ToLower(name)
+
+	users := []ManifestEntry{}
+	for _, entry := range m.Entries() {
+		if strings.ToLower(entry.Namespace()) == name {
+			users = append(users, entry)
+		}
+	}
+
+	return users
+}
+
 func (m Manifest) SingleByName(name string) ManifestSingle {
+	name = strings.ToLower(name)
 	for _, single := range m.Singles {
 		if strings.ToLower(single.Name) == name {
 			return single
@@ -87,6 +282,100 @@ func (m Manifest) SingleByName(name string) ManifestSingle {
 	return ManifestSingle{}
 }
 
+type ManifestNamespace struct {
+	Name        string              `yaml:"name"`                  // Name of the namespace (required)
+	Managed     bool                `yaml:"managed"`               // Create the namespace and keep it in sync, otherwise it is only checked for drift
+	Labels      map[string]string   `yaml:"labels,omitempty"`      // Labels set on the namespace
+	Annotations map[string]string   `yaml:"annotations,omitempty"` // Annotations set on the namespace
+	Quota       map[string]string   `yaml:"quota,omitempty"`       // Hard limits of the ResourceQuota of the namespace, eg. requests.cpu: "4"
+	LimitRange  *ManifestLimitRange `yaml:"limit_range,omitempty"` // Container defaults and bounds of the LimitRange of the namespace
+}
+
+func (m ManifestNamespace) String() string {
+	return m.Name
+}
+
+type ManifestLimitRange struct {
+	Default        map[string]string `yaml:"default,omitempty"`         // Limits of containers which set none
+	DefaultRequest map[string]string `yaml:"default_request,omitempty"` // Requests of containers which set none
+	Max            map[string]string `yaml:"max,omitempty"`             // Largest limits a container may set
+	Min            map[string]string `yaml:"min,omitempty"`             // Smallest requests a container may set
+}
+
+type TimeoutOperation string
+
+const (
+	TimeoutQuery  TimeoutOperation = "query"  // reading from helm, kubectl and git
+	TimeoutRepo   TimeoutOperation = "repo"   // updating repos, pulling charts and building chart dependencies
+	TimeoutDeploy TimeoutOperation = "deploy" // changing the cluster
+)
+
+var DefaultTimeouts = map[TimeoutOperation]time.Duration{
+	TimeoutQuery:  time.Minute,
+	TimeoutRepo:   5 * time.Minute,
+	TimeoutDeploy: 10 * time.Minute,
+}
+
+type ManifestTimeouts struct {
+	Query  string `yaml:"query,omitempty"`  // Timeout of commands which only read (defaults to 1m)
+	Repo   string `yaml:"repo,omitempty"`   // Timeout of updating repos and fetching charts (defaults to 5m)
+	Deploy string `yaml:"deploy,omitempty"` // Timeout of commands which change the cluster (defaults to 10m)
+}
+
+func (m ManifestTimeouts) Duration(op TimeoutOperation) (time.Duration, error) {
+	value := map[TimeoutOperation]string{
+		TimeoutQuery:  m.Query,
+		TimeoutRepo:   m.Repo,
+		TimeoutDeploy: m.Deploy,
+	}[op]
+
+	if value == "" {
+		return DefaultTimeouts[op], nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+type ManifestLease struct {
+	Disabled  bool   `yaml:"disabled,omitempty"`  // Do not lock the cluster
+	Name      string `yaml:"name,omitempty"`      // Name of the Lease object (defaults to helm-manager)
+	Namespace string `yaml:"namespace,omitempty"` // Namespace of the Lease object (defaults to default)
+	TTL       string `yaml:"ttl,omitempty"`       // How long the lease is valid without being renewed (defaults to 15m)
+	Wait      string `yaml:"wait,omitempty"`      // How long to wait for someone else's lease before failing (defaults to not waiting)
+}
+
+func (m ManifestLease) NameOrDefault() string {
+	if m.Name == "" {
+		return DefaultLeaseName
+	}
+
+	return m.Name
+}
+
+func (m ManifestLease) NamespaceOrDefault() string {
+	if m.Namespace == "" {
+		return DefaultLeaseNamespace
+	}
+
+	return m.Namespace
+}
+
+func (m ManifestLease) TTLDuration() (time.Duration, error) {
+	if m.TTL == "" {
+		return DefaultLeaseTTL, nil
+	}
+
+	return time.ParseDuration(m.TTL)
+}
+
+func (m ManifestLease) WaitDuration() (time.Duration, error) {
+	if m.Wait == "" {
+		return 0, nil
+	}
+
+	return time.ParseDuration(m.Wait)
+}
+
 type ManifestRepo struct {
 	Name string `yaml:"name"` // Name of the repo
 	URL  string `yaml:"url"`  // URL of the repo
@@ -97,9 +386,23 @@ func (m ManifestRepo) String() string {
 }
 
 type ManifestRelease struct {
-	Name      string        `yaml:"name"`      // the release name (required)
-	Namespace string        `yaml:"namespace"` // the namespace the release is installed in (defaults to "default")
-	Chart     ManifestChart `yaml:"chart"`     // The chart to install (required)
+	Name      string            `yaml:"name"`                 // the release name (required)
+	Namespace string            `yaml:"namespace"`            // the namespace the release is installed in (defaults to "default")
+	Chart     ManifestChart     `yaml:"chart"`                // The chart to install (required)
+	Hooks     ManifestHooks     `yaml:"hooks,omitempty"`      // Commands to run around deploys and removals
+	DependsOn []string          `yaml:"depends_on,omitempty"` // Releases or singles which must be deployed before this release, as name, release/name or single/name
+	Waivers   ManifestWaivers   `yaml:"waivers,omitempty"`    // Policy rules this release may violate until the waiver expires
+	Timeout   string            `yaml:"timeout,omitempty"`    // How long deploying, removing or rolling back the release may take, overrides timeouts.deploy
+	Labels    map[string]string `yaml:"labels,omitempty"`     // Labels to select the release by, like tier: backend
+}
+
+// DeployTimeout is how long changing the release in the cluster may take.
+func (m ManifestRelease) DeployTimeout(timeouts ManifestTimeouts) (time.Duration, error) {
+	if m.Timeout == "" {
+		return timeouts.Duration(TimeoutDeploy)
+	}
+
+	return time.ParseDuration(m.Timeout)
 }
 
 func (m ManifestRelease) String() string {
@@ -126,16 +429,264 @@ func (m ManifestChart) RepoName() string {
 }
 
 type ManifestSingle struct {
-	Name      string `yaml:"name"`       // Name of the single
-	UseCreate bool   `yaml:"use_create"` // Use create instead of apply
-	Namespace string `yaml:"namespace"`  // Namespace to install the single in (optional)
+	Name      string            `yaml:"name"`                 // Name of the single
+	UseCreate bool              `yaml:"use_create"`           // Use create instead of apply
+	Namespace string            `yaml:"namespace"`            // Namespace to install the single in (optional)
+	Hooks     ManifestHooks     `yaml:"hooks,omitempty"`      // Commands to run around deploys and removals
+	DependsOn []string          `yaml:"depends_on,omitempty"` // Releases or singles which must be deployed before this single, as name, release/name or single/name
+	Waivers   ManifestWaivers   `yaml:"waivers,omitempty"`    // Policy rules this single may violate until the waiver expires
+	Labels    map[string]string `yaml:"labels,omitempty"`     // Labels to select the single by, like tier: backend
 }
 
 func (m ManifestSingle) String() string {
 	return m.Name
 }
 
+type HookEvent string
+
+const (
+	HookPreDeploy  HookEvent = "pre_deploy"
+	HookPostDeploy HookEvent = "post_deploy"
+	HookPreRemove  HookEvent = "pre_remove"
+	HookPostRemove HookEvent = "post_remove"
+	HookOnFailure  HookEvent = "on_failure"
+)
+
+const DefaultHookTimeout = 5 * time.Minute
+
+type ManifestHooks struct {
+	PreDeploy  []ManifestHook `yaml:"pre_deploy,omitempty"`  // Run before the release or single is deployed
+	PostDeploy []ManifestHook `yaml:"post_deploy,omitempty"` // Run after a successful deploy
+	PreRemove  []ManifestHook `yaml:"pre_remove,omitempty"`  // Run before the release or single is removed from the cluster
+	PostRemove []ManifestHook `yaml:"post_remove,omitempty"` // Run after a successful removal
+	OnFailure  []ManifestHook `yaml:"on_failure,omitempty"`  // Run when a deploy, removal or one of the other hooks fails
+}
+
+func (m ManifestHooks) ByEvent(event HookEvent) []ManifestHook {
+	switch event {
+	case HookPreDeploy:
+		return m.PreDeploy
+	case HookPostDeploy:
+		return m.PostDeploy
+	case HookPreRemove:
+		return m.PreRemove
+	case HookPostRemove:
+		return m.PostRemove
+	case HookOnFailure:
+		return m.OnFailure
+	}
+
+	return nil
+}
+
+type ManifestHook struct {
+	Name    string `yaml:"name,omitempty"`    // Name of the hook, used in logs (optional)
+	Command string `yaml:"command"`           // Command to run, it is passed to the system shell
+	Timeout string `yaml:"timeout,omitempty"` // Maximum duration of the hook, defaults to 5m
+}
+
+func (m ManifestHook) String() string {
+	if m.Name != "" {
+		return m.Name
+	}
+
+	return m.Command
+}
+
+func (m ManifestHook) TimeoutDuration() (time.Duration, error) {
+	if m.Timeout == "" {
+		return DefaultHookTimeout, nil
+	}
+
+	return time.ParseDuration(m.Timeout)
+}
+
 type ReleaseLock struct {
 	Chart   string `yaml:"chart"`
 	Version string `yaml:"version"`
+	Digest  string `yaml:"digest,omitempty"` // sha256 of the chart archive, recorded on deploy
+	Signer  string `yaml:"signer,omitempty"` // Fingerprint of the key which signed the chart, when a keyring is configured
+}
+
+const (
+	EntryKindRelease = "release"
+	EntryKindSingle  = "single"
+)
+
+// ManifestEntry is either a release or a single, used where both are handled together such as dependency ordering.
+type ManifestEntry struct {
+	Kind    string
+	Release ManifestRelease
+	Single  ManifestSingle
+}
+
+func (e ManifestEntry) Name() string {
+	if e.Kind == EntryKindRelease {
+		return e.Release.Name
+	}
+
+	return e.Single.Name
+}
+
+func (e ManifestEntry) DependsOn() []string {
+	if e.Kind == EntryKindRelease {
+		return e.Release.DependsOn
+	}
+
+	return e.Single.DependsOn
+}
+
+// Namespace is the namespace the entry is installed in, empty for singles which leave it to their resources.
+func (e ManifestEntry) Namespace() string {
+	if e.Kind == EntryKindSingle {
+		return e.Single.Namespace
+	}
+
+	if e.Release.Namespace == "" {
+		return "default"
+	}
+
+	return e.Release.Namespace
+}
+
+func (e ManifestEntry) String() string {
+	return fmt.Sprintf("%s %s", e.Kind, e.Name())
+}
+
+// Key identifies the entry, a release and a single can share a name.
+func (e ManifestEntry) Key() string {
+	return entryKey(e.Kind, e.Name())
+}
+
+func entryKey(kind string, name string) string {
+	return kind + "/" + strings.ToLower(name)
+}
+
+// ParseDependency splits a depends_on entry into the kind and the lowercase name it refers to.
+// The kind is empty for a plain name, which refers to the release and the single of that name.
+func ParseDependency(dep string) (string, string) {
+	dep = strings.ToLower(dep)
+	for _, kind := range []string{EntryKindRelease, EntryKindSingle} {
+		if name := strings.TrimPrefix(dep, kind+"/"); name != dep {
+			return kind, name
+		}
+	}
+
+	return "", dep
+}
+
+// dependencyKeys are the keys of the entries a depends_on entry refers to.
+func dependencyKeys(dep string) []string {
+	kind, name := ParseDependency(dep)
+	if kind != "" {
+		return []string{entryKey(kind, name)}
+	}
+
+	return []string{entryKey(EntryKindRelease, name), entryKey(EntryKindSingle, name)}
+}
+
+// dependsOnKey reports if the entry lists a dependency referring to key.
+func (e ManifestEntry) dependsOnKey(key string) bool {
+	for _, dep := range e.DependsOn() {
+		for _, depKey := range dependencyKeys(dep) {
+			if depKey == key {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Entries returns every release followed by every single, in manifest order.
+func (m Manifest) Entries() []ManifestEntry {
+	entries := make([]ManifestEntry, 0, len(m.Releases)+len(m.Singles))
+	for _, release := range m.Releases {
+		entries = append(entries, ManifestEntry{Kind: EntryKindRelease, Release: release})
+	}
+
+	for _, single := range m.Singles {
+		entries = append(entries, ManifestEntry{Kind: EntryKindSingle, Single: single})
+	}
+
+	return entries
+}
+
+// Dependents returns every entry which directly or transitively depends on one of the given entries, in manifest order.
+func (m Manifest) Dependents(of []ManifestEntry) []ManifestEntry {
+	found := map[string]bool{}
+	queue := []string{}
+	for _, entry := range of {
+		queue = append(queue, entry.Key())
+	}
+
+	entries := m.Entries()
+	for len(queue) != 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		for _, entry := range entries {
+			if !found[entry.Key()] && entry.dependsOnKey(key) {
+				found[entry.Key()] = true
+				queue = append(queue, entry.Key())
+			}
+		}
+	}
+
+	result := make([]ManifestEntry, 0, len(found))
+	for _, entry := range entries {
+		if found[entry.Key()] {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// SortEntries orders entries so that every entry comes after the entries it depends on, otherwise keeping the given order.
+// Dependencies which are not part of entries are ignored, a name shared by a release and a single waits for both.
+func SortEntries(entries []ManifestEntry) ([]ManifestEntry, error) {
+	present := map[string]bool{}
+	for _, entry := range entries {
+		present[entry.Key()] = true
+	}
+
+	done := map[string]bool{}
+	result := make([]ManifestEntry, 0, len(entries))
+	for len(result) != len(entries) {
+		progress := false
+		for _, entry := range entries {
+			if done[entry.Key()] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range entry.DependsOn() {
+				for _, key := range dependencyKeys(dep) {
+					if present[key] && !done[key] {
+						ready = false
+					}
+				}
+			}
+
+			if ready {
+				done[entry.Key()] = true
+				result = append(result, entry)
+				progress = true
+			}
+		}
+
+		if !progress {
+			stuck := []string{}
+			for _, entry := range entries {
+				if !done[entry.Key()] {
+					stuck = append(stuck, entry.String())
+				}
+			}
+
+			return nil, fmt.Errorf("dependency cycle between %s", strings.Join(stuck, ", "))
+		}
+	}
+
+	return result, nil
 }
@@ -0,0 +1,183 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PolicyCheck is one of the built-in checks a policy rule can run against rendered resources.
+type PolicyCheck string
+
+const (
+	PolicyCheckNoLatestTag   PolicyCheck = "no_latest_tag"  // images must be pinned to a tag other than latest, or a digest
+	PolicyCheckRequireLimits PolicyCheck = "require_limits" // containers must set resource limits
+	PolicyCheckNoPrivileged  PolicyCheck = "no_privileged"  // containers must not run privileged
+	PolicyCheckRequireLabels PolicyCheck = "require_labels" // resources must carry the listed labels
+)
+
+const (
+	PolicyFileName   = "policy.yaml" // name of the policy file in the context
+	WaiverDateLayout = "2006-01-02"  // layout of the expires field of a waiver
+)
+
+var PolicyChecks = []PolicyCheck{PolicyCheckNoLatestTag, PolicyCheckRequireLimits, PolicyCheckNoPrivileged, PolicyCheckRequireLabels}
+
+// DefaultLimitResources are the limits require_limits checks for when a rule does not list any.
+var DefaultLimitResources = []string{"cpu", "memory"}
+
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+type PolicyRule struct {
+	Name      string      `yaml:"name"`                // Name used to waive the rule (required)
+	Check     PolicyCheck `yaml:"check"`               // The built-in check to run (required)
+	Severity  Severity    `yaml:"severity,omitempty"`  // error blocks the deploy, warning only reports it (defaults to error)
+	Kinds     []string    `yaml:"kinds,omitempty"`     // Only check resources of these kinds (optional)
+	Labels    []string    `yaml:"labels,omitempty"`    // Labels which must be set, for require_labels
+	Resources []string    `yaml:"resources,omitempty"` // Limits which must be set, for require_limits (defaults to cpu and memory)
+}
+
+func (r PolicyRule) EffectiveSeverity() Severity {
+	if r.Severity == "" {
+		return SeverityError
+	}
+
+	return r.Severity
+}
+
+func (r PolicyRule) AppliesTo(kind string) bool {
+	if len(r.Kinds) == 0 {
+		return true
+	}
+
+	for _, k := range r.Kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p Policy) RuleByName(name string) PolicyRule {
+	for _, rule := range p.Rules {
+		if strings.EqualFold(rule.Name, name) {
+			return rule
+		}
+	}
+
+	return PolicyRule{}
+}
+
+// Validate checks the policy for problems, like Manifest.Validate only the Field of the diagnostics is set.
+func (p Policy) Validate() Diagnostics {
+	diags := Diagnostics{}
+	errorf := func(field string, format string, args ...any) {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Field:    field,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	names := map[string]bool{}
+	for i, rule := range p.Rules {
+		field := fmt.Sprintf("rules[%d]", i)
+		if rule.Name == "" {
+			errorf(field+".name", "policy rule name cannot be empty")
+		} else if names[strings.ToLower(rule.Name)] {
+			errorf(field+".name", "policy rule \"%s\" is defined more than once", rule.Name)
+		}
+
+		names[strings.ToLower(rule.Name)] = true
+
+		known := false
+		for _, check := range PolicyChecks {
+			known = known || rule.Check == check
+		}
+
+		if !known {
+			errorf(field+".check", "policy rule \"%s\" has unknown check \"%s\", expected one of %v", rule.Name, rule.Check, PolicyChecks)
+		}
+
+		if rule.Severity != "" && rule.Severity != SeverityError && rule.Severity != SeverityWarning {
+			errorf(field+".severity", "policy rule \"%s\" severity must be error or warning", rule.Name)
+		}
+
+		if rule.Check == PolicyCheckRequireLabels && len(rule.Labels) == 0 {
+			errorf(field+".labels", "policy rule \"%s\" requires labels but does not list any", rule.Name)
+		}
+	}
+
+	return diags
+}
+
+// ManifestWaiver lets a release or single be deployed even though it violates a policy rule, until the waiver expires.
+type ManifestWaiver struct {
+	Rule    string `yaml:"rule"`    // Name of the waived policy rule (required)
+	Reason  string `yaml:"reason"`  // Why the rule is waived (required)
+	Expires string `yaml:"expires"` // Last day the waiver applies, as YYYY-MM-DD (required)
+}
+
+// ExpiresAt is the end of the expiry day, waivers are valid for the whole day they expire on.
+func (w ManifestWaiver) ExpiresAt() (time.Time, error) {
+	date, err := time.ParseInLocation(WaiverDateLayout, w.Expires, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return date.AddDate(0, 0, 1), nil
+}
+
+func (w ManifestWaiver) Expired(now time.Time) bool {
+	expires, err := w.ExpiresAt()
+	return err != nil || !now.Before(expires)
+}
+
+type ManifestWaivers []ManifestWaiver
+
+// Find returns the waiver for a rule, the second value is false when there is none.
+func (w ManifestWaivers) Find(rule string) (ManifestWaiver, bool) {
+	for _, waiver := range w {
+		if strings.EqualFold(waiver.Rule, rule) {
+			return waiver, true
+		}
+	}
+
+	return ManifestWaiver{}, false
+}
+
+func validateWaivers(field string, name string, waivers ManifestWaivers, errorf func(field string, format string, args ...any)) {
+	rules := map[string]bool{}
+	for i, waiver := range waivers {
+		waiverField := fmt.Sprintf("%s[%d]", field, i)
+		if waiver.Rule == "" {
+			errorf(waiverField+".rule", "waiver of \"%s\" has no rule", name)
+		} else if rules[strings.ToLower(waiver.Rule)] {
+			errorf(waiverField+".rule", "\"%s\" waives rule \"%s\" more than once", name, waiver.Rule)
+		}
+
+		rules[strings.ToLower(waiver.Rule)] = true
+
+		if strings.TrimSpace(waiver.Reason) == "" {
+			errorf(waiverField+".reason", "waiver of rule \"%s\" for \"%s\" has no reason", waiver.Rule, name)
+		}
+
+		if _, err := waiver.ExpiresAt(); err != nil {
+			errorf(waiverField+".expires", "waiver of rule \"%s\" for \"%s\" has invalid expiry \"%s\", expected YYYY-MM-DD", waiver.Rule, name, waiver.Expires)
+		}
+	}
+}
+
+type PolicyViolation struct {
+	Rule     string
+	Severity Severity
+	Resource string // Kind/name of the offending resource
+	Message  string
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("[%s] %s: %s", v.Rule, v.Resource, v.Message)
+}
@@ -0,0 +1,198 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	LabelKeyRegex   = regexp.MustCompile(`^([a-z0-9]([-a-z0-9.]*[a-z0-9])?/)?[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+	LabelValueRegex = regexp.MustCompile(`^([a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?)?$`)
+)
+
+// SelectorRequirement is one comma separated part of a selector, like "namespace=db" or "chart!=mongodb".
+type SelectorRequirement struct {
+	Key   string
+	Value string
+	Not   bool
+	// Exists only checks if the key is set to anything, "key" requires it to be set and "!key" requires it not to be.
+	Exists bool
+}
+
+func (r SelectorRequirement) Matches(fields map[string]string) bool {
+	value, ok := fields[r.Key]
+	if r.Exists {
+		return (ok && value != "") != r.Not
+	}
+
+	return (ok && value == r.Value) != r.Not
+}
+
+func (r SelectorRequirement) String() string {
+	switch {
+	case r.Exists && r.Not:
+		return "!" + r.Key
+	case r.Exists:
+		return r.Key
+	case r.Not:
+		return fmt.Sprintf("%s!=%s", r.Key, r.Value)
+	default:
+		return fmt.Sprintf("%s=%s", r.Key, r.Value)
+	}
+}
+
+// Selector matches items by their fields, every requirement has to match.
+type Selector []SelectorRequirement
+
+// SelectorFielder is implemented by everything a selector can match.
+// Labels are matched along with built in fields like name and namespace, a label with the same key hides the field.
+type SelectorFielder interface {
+	SelectorFields() map[string]string
+}
+
+func ParseSelector(selector string) (Selector, error) {
+	s := Selector{}
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		r := SelectorRequirement{}
+		if idx := strings.Index(part, "!="); idx != -1 {
+			r.Key, r.Value, r.Not = part[:idx], part[idx+2:], true
+		} else if idx := strings.Index(part, "=="); idx != -1 {
+			r.Key, r.Value = part[:idx], part[idx+2:]
+		} else if idx := strings.Index(part, "="); idx != -1 {
+			r.Key, r.Value = part[:idx], part[idx+1:]
+		} else {
+			r.Key, r.Exists = strings.TrimPrefix(part, "!"), true
+			r.Not = strings.HasPrefix(part, "!")
+		}
+
+		r.Key = strings.TrimSpace(r.Key)
+		r.Value = strings.TrimSpace(r.Value)
+		if r.Key == "" || strings.ContainsAny(r.Key, "!=") || strings.ContainsAny(r.Value, "!=") {
+			return nil, fmt.Errorf("invalid selector %q", part)
+		}
+
+		s = append(s, r)
+	}
+
+	if len(s) == 0 {
+		return nil, fmt.Errorf("empty selector %q", selector)
+	}
+
+	return s, nil
+}
+
+func (s Selector) Matches(item SelectorFielder) bool {
+	fields := item.SelectorFields()
+	for _, r := range s {
+		if !r.Matches(fields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		parts[i] = r.String()
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// MatchSelectorInput matches the search input of an interactive selector as a selector when it looks like one, "tier=backend" or "!canary".
+// ok is false for any other input, which is then matched as plain text.
+func MatchSelectorInput(input string, item SelectorFielder) (matched bool, ok bool) {
+	input = strings.TrimSpace(input)
+	if !strings.ContainsAny(input, "=!") {
+		return false, false
+	}
+
+	selector, err := ParseSelector(input)
+	if err != nil {
+		return false, false
+	}
+
+	return selector.Matches(item), true
+}
+
+// SelectMatching returns the items the selector matches, in order.
+func SelectMatching[T SelectorFielder](s Selector, items []T) []T {
+	matched := []T{}
+	for _, item := range items {
+		if s.Matches(item) {
+			matched = append(matched, item)
+		}
+	}
+
+	return matched
+}
+
+func withLabels(fields map[string]string, labels map[string]string) map[string]string {
+	for k, v := range labels {
+		fields[k] = v
+	}
+
+	return fields
+}
+
+// FormatLabels lists labels as "key=value" sorted by key.
+func FormatLabels(labels map[string]string) string {
+	parts := make([]string, 0, len(labels))
+	for k, v := range labels {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+func validateLabels(field string, name string, labels map[string]string, errorf func(field string, format string, args ...any)) {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if v := labels[k]; !LabelKeyRegex.MatchString(k) {
+			errorf(field, "label \"%s\" of \"%s\" is not a valid label key", k, name)
+		} else if !LabelValueRegex.MatchString(v) {
+			errorf(field+"."+k, "label \"%s\" of \"%s\" has invalid value \"%s\"", k, name, v)
+		}
+	}
+}
+
+func (m ManifestRelease) SelectorFields() map[string]string {
+	return withLabels(map[string]string{
+		"name":      m.Name,
+		"namespace": m.Namespace,
+		"chart":     m.Chart.Name,
+		"repo":      m.Chart.Repo,
+		"version":   m.Chart.Version,
+	}, m.Labels)
+}
+
+func (m ManifestSingle) SelectorFields() map[string]string {
+	return withLabels(map[string]string{
+		"name":      m.Name,
+		"namespace": m.Namespace,
+	}, m.Labels)
+}
+
+func (m HelmRelease) SelectorFields() map[string]string {
+	return map[string]string{
+		"name":      m.Name,
+		"namespace": m.Namespace,
+		"chart":     m.Chart(),
+		"version":   m.Version(),
+	}
+}
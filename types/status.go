@@ -0,0 +1,40 @@
+package types
+
+type ReleaseStatus struct {
+	Name            string   `json:"name"`
+	Namespace       string   `json:"namespace"`
+	Chart           string   `json:"chart"`
+	Version         string   `json:"version"`                    // version pinned in the manifest
+	DeployedVersion string   `json:"deployed_version,omitempty"` // version installed in the cluster
+	Status          string   `json:"status"`                     // status reported by helm, or "not deployed"
+	Drift           []string `json:"drift,omitempty"`
+}
+
+type NamespaceStatus struct {
+	Name    string   `json:"name"`
+	Managed bool     `json:"managed"`
+	Drift   []string `json:"drift,omitempty"`
+}
+
+// Status compares the manifest to the cluster.
+type Status struct {
+	Releases   []ReleaseStatus   `json:"releases"`
+	Namespaces []NamespaceStatus `json:"namespaces"`
+}
+
+func (s Status) Drifted() int {
+	drifted := 0
+	for _, r := range s.Releases {
+		if len(r.Drift) != 0 {
+			drifted++
+		}
+	}
+
+	for _, ns := range s.Namespaces {
+		if len(ns.Drift) != 0 {
+			drifted++
+		}
+	}
+
+	return drifted
+}
@@ -76,15 +76,21 @@ func (m ManifestRelease) Details() string {
   %s	%s
   %s	%s
   %s	%s
+  %s	%s
 `,
 		faintColor.Sprint("Name:"), m.Name,
 		faintColor.Sprint("Namespace:"), m.Namespace,
 		faintColor.Sprint("Version:"), m.Chart.Version,
 		faintColor.Sprint("Chart:"), m.Chart.RepoName(),
+		faintColor.Sprint("Labels:"), FormatLabels(m.Labels),
 	)
 }
 
 func (m ManifestRelease) Match(input string) bool {
+	if matched, ok := MatchSelectorInput(input, m); ok {
+		return matched
+	}
+
 	return strings.Contains(strings.ToLower(m.Name), strings.ToLower(input)) ||
 		strings.Contains(strings.ToLower(m.Namespace), strings.ToLower(input)) ||
 		m.Chart.Match(input)
@@ -141,14 +147,20 @@ func (m ManifestSingle) Details() string {
   %s	%s
 %s	%t
   %s	%s
+  %s	%s
 `,
 		faintColor.Sprint("Name:"), m.Name,
 		faintColor.Sprint("UseCreate:"), m.UseCreate,
 		faintColor.Sprint("Namespace:"), m.Namespace,
+		faintColor.Sprint("Labels:"), FormatLabels(m.Labels),
 	)
 }
 
 func (m ManifestSingle) Match(input string) bool {
+	if matched, ok := MatchSelectorInput(input, m); ok {
+		return matched
+	}
+
 	inputs := strings.Split(strings.ToLower(input), " ")
 	for _, i := range inputs {
 		if !(strings.Contains(strings.ToLower(m.Name), i) ||
@@ -301,3 +313,36 @@ func (m HelmRelease) Match(input string) bool {
 		strings.Contains(strings.ToLower(m.Version()), strings.ToLower(input)) ||
 		strings.Contains(strings.ToLower(m.Chart()), strings.ToLower(input))
 }
+
+func (m HelmReleaseRevision) Label() string {
+	return fmt.Sprintf("%s - %s (%s)", color.CyanString("%d", m.Revision), color.RedString(m.Version()), faintColor.Sprint(m.Status))
+}
+
+func (m HelmReleaseRevision) Selected() string {
+	return fmt.Sprint(m.Revision)
+}
+
+func (m HelmReleaseRevision) Details() string {
+	return fmt.Sprintf(`
+--------- Revision ----------
+  %s	%d
+  %s	%s
+  %s	%s
+  %s	%s
+  %s	%s
+  %s	%s
+`,
+		faintColor.Sprint("Revision:"), m.Revision,
+		faintColor.Sprint("Updated:"), m.Updated,
+		faintColor.Sprint("Status:"), m.Status,
+		faintColor.Sprint("Chart:"), m.ChartVersion,
+		faintColor.Sprint("AppVersion:"), m.AppVersion,
+		faintColor.Sprint("Description:"), m.Description,
+	)
+}
+
+func (m HelmReleaseRevision) Match(input string) bool {
+	return strings.Contains(fmt.Sprint(m.Revision), input) ||
+		strings.Contains(strings.ToLower(m.ChartVersion), strings.ToLower(input)) ||
+		strings.Contains(strings.ToLower(m.Status), strings.ToLower(input))
+}
@@ -89,20 +89,62 @@ type Validator[T any] interface {
 
 type ValidatorFunction[T any] func(T) error
 
+// Completer is implemented by validators which only accept a known set of values, shell completion offers them.
+// A nil slice means the values are not known.
+type Completer interface {
+	Completions() ([]string, error)
+}
+
+type multiValidator[T any] struct {
+	ValidatorFunction[T]
+	validators []Validator[T]
+}
+
 func MultiValidator[T any](validators ...Validator[T]) Validator[T] {
-	return ValidatorFunction[T](func(item T) error {
-		for _, validator := range validators {
-			if err := validator.Validate(item); err != nil {
-				if errors.Is(err, ErrValidtorStopValidation) {
-					return nil
+	return multiValidator[T]{
+		ValidatorFunction: ValidatorFunction[T](func(item T) error {
+			for _, validator := range validators {
+				if err := validator.Validate(item); err != nil {
+					if errors.Is(err, ErrValidtorStopValidation) {
+						return nil
+					}
+
+					return err
 				}
+			}
 
-				return err
+			return nil
+		}),
+		validators: validators,
+	}
+}
+
+// Completions are the values known by any of the validators which pass all of them.
+func (v multiValidator[T]) Completions() ([]string, error) {
+	var values []string
+	for _, validator := range v.validators {
+		completer, ok := validator.(Completer)
+		if !ok {
+			continue
+		}
+
+		items, err := completer.Completions()
+		if err != nil {
+			return nil, err
+		}
+
+		if values == nil {
+			values = []string{}
+		}
+
+		for _, item := range items {
+			if t, err := v.Convert(item); err == nil && v.Validate(t) == nil {
+				values = append(values, item)
 			}
 		}
+	}
 
-		return nil
-	})
+	return values, nil
 }
 
 func NotEqualValidator[T comparable](template Stringer, future Future[[]T]) Validator[T] {
@@ -124,23 +166,45 @@ func NotEqualValidator[T comparable](template Stringer, future Future[[]T]) Vali
 	})
 }
 
+type equalValidator[T comparable] struct {
+	ValidatorFunction[T]
+	future Future[[]T]
+}
+
 func EqualValidator[T comparable](format Stringer, future Future[[]T]) Validator[T] {
-	return ValidatorFunction[T](func(val T) error {
-		val = fuzzyEqual(val)
+	return equalValidator[T]{
+		ValidatorFunction: ValidatorFunction[T](func(val T) error {
+			val = fuzzyEqual(val)
 
-		items, err := future.Get()
-		if err != nil {
-			return err
-		}
+			items, err := future.Get()
+			if err != nil {
+				return err
+			}
 
-		for _, item := range items {
-			if val == fuzzyEqual(item) {
-				return nil
+			for _, item := range items {
+				if val == fuzzyEqual(item) {
+					return nil
+				}
 			}
-		}
 
-		return fmt.Errorf(format.String(), val)
-	})
+			return fmt.Errorf(format.String(), val)
+		}),
+		future: future,
+	}
+}
+
+func (v equalValidator[T]) Completions() ([]string, error) {
+	items, err := v.future.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = fmt.Sprint(item)
+	}
+
+	return values, nil
 }
 
 func NameValidator(name string, allowEmpty bool) Validator[string] {
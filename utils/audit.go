@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/seventv/helm-manager/v2/logger"
+	"github.com/seventv/helm-manager/v2/types"
+)
+
+// AppendAudit appends a single json line to the audit log.
+// The file is locked for the duration of the write so concurrent runs never interleave entries.
+func AppendAudit(file string, entry types.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, true); err != nil {
+		return fmt.Errorf("failed to lock audit log, %v", err)
+	}
+	defer unlockFile(f)
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+func ReadAudit(file string) ([]types.AuditEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, false); err != nil {
+		return nil, fmt.Errorf("failed to lock audit log, %v", err)
+	}
+	defer unlockFile(f)
+
+	entries := []types.AuditEntry{}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		entry := types.AuditEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logger.Warnf("skipping malformed audit log entry on line %d: %s", line, err)
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
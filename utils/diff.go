@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// DiffLines compares two texts line by line, returning every line prefixed with "-" if it was removed, "+" if it was added or " " if it is in both.
+func DiffLines(old string, new string) []string {
+	a := strings.Split(strings.TrimSuffix(old, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(new, "\n"), "\n")
+
+	// the common start and end are skipped, so the table below only covers the lines which changed
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ma := a[prefix : len(a)-suffix]
+	mb := b[prefix : len(b)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of ma[i:] and mb[j:]
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		lines = append(lines, " "+line)
+	}
+
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			lines = append(lines, " "+ma[i])
+			i++
+			j++
+		case j < len(mb) && (i == len(ma) || lcs[i][j+1] > lcs[i+1][j]):
+			lines = append(lines, "+"+mb[j])
+			j++
+		default:
+			lines = append(lines, "-"+ma[i])
+			i++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, " "+line)
+	}
+
+	return lines
+}
+
+// DiffHunks colors the changed lines of DiffLines, keeping only context lines around the changes.
+// It returns nothing when the texts are the same.
+func DiffHunks(lines []string, context int) []string {
+	changed := make([]bool, len(lines))
+	different := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, " ") {
+			continue
+		}
+
+		different = true
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(lines) {
+				changed[j] = true
+			}
+		}
+	}
+
+	if !different {
+		return nil
+	}
+
+	hunks := []string{}
+	skipped := false
+	for i, line := range lines {
+		if !changed[i] {
+			skipped = true
+			continue
+		}
+
+		if skipped && len(hunks) != 0 {
+			hunks = append(hunks, color.New(color.Faint).Sprint("..."))
+		}
+		skipped = false
+
+		switch line[0] {
+		case '+':
+			hunks = append(hunks, color.GreenString("%s", line))
+		case '-':
+			hunks = append(hunks, color.RedString("%s", line))
+		default:
+			hunks = append(hunks, line)
+		}
+	}
+
+	return hunks
+}
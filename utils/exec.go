@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seventv/helm-manager/v2/logger"
+)
+
+// CommandGracePeriod is how long a cancelled command gets to exit after being interrupted, before it is killed.
+var CommandGracePeriod = 10 * time.Second
+
+// StoppedCommand is a command which did not finish because its context was cancelled or timed out.
+type StoppedCommand struct {
+	Command  string
+	TimedOut bool
+}
+
+var stopped = struct {
+	mtx      sync.Mutex
+	commands []StoppedCommand
+}{}
+
+// StoppedCommands returns the commands which were cancelled or timed out so far.
+func StoppedCommands() []StoppedCommand {
+	stopped.mtx.Lock()
+	defer stopped.mtx.Unlock()
+
+	return append([]StoppedCommand(nil), stopped.commands...)
+}
+
+// captureOutput copies what cmd writes to a buffer as well, so it can be logged. Terminals are left alone, they are handed to the command as they are.
+func captureOutput(cmd *exec.Cmd) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+
+	capture := func(w io.Writer) io.Writer {
+		if w == nil {
+			return nil
+		}
+
+		if _, ok := w.(*os.File); ok {
+			return w
+		}
+
+		return io.MultiWriter(w, buf)
+	}
+
+	if cmd.Stdout == cmd.Stderr {
+		cmd.Stdout = capture(cmd.Stdout)
+		cmd.Stderr = cmd.Stdout
+	} else {
+		cmd.Stdout = capture(cmd.Stdout)
+		cmd.Stderr = capture(cmd.Stderr)
+	}
+
+	return buf
+}
+
+// runCommand runs cmd and logs it with its duration and exit code, see waitCommand.
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	line := strings.Join(cmd.Args, " ")
+
+	var output *bytes.Buffer
+	if logger.LogsCommandOutput() {
+		output = captureOutput(cmd)
+	}
+
+	start := time.Now()
+	err := waitCommand(ctx, cmd, line)
+
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+
+	var data []byte
+	if output != nil {
+		data = output.Bytes()
+	}
+
+	logger.Command(line, time.Since(start), exitCode, data)
+	return err
+}
+
+// waitCommand runs cmd until it exits or ctx is done. When ctx is done the command is interrupted so it can clean up,
+// and killed if it is still running after CommandGracePeriod.
+func waitCommand(ctx context.Context, cmd *exec.Cmd, line string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("not running %s, %w", cmd.Args[0], err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	// windows cannot deliver interrupts to other processes
+	if runtime.GOOS == "windows" || cmd.Process.Signal(os.Interrupt) != nil {
+		_ = cmd.Process.Kill()
+	}
+
+	select {
+	case <-done:
+	case <-time.After(CommandGracePeriod):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+
+	stopped.mtx.Lock()
+	stopped.commands = append(stopped.commands, StoppedCommand{Command: line, TimedOut: timedOut})
+	stopped.mtx.Unlock()
+
+	if timedOut {
+		return fmt.Errorf("%s timed out, %w", cmd.Args[0], ctx.Err())
+	}
+
+	return fmt.Errorf("%s was cancelled, %w", cmd.Args[0], ctx.Err())
+}
+
+// ExecuteCommand runs a command, returning its combined stdout and stderr.
+func ExecuteCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return ExecuteCommandStdin(ctx, nil, name, args...)
+}
+
+func ExecuteCommandStdin(ctx context.Context, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+
+	out := &bytes.Buffer{}
+	cmd.Stdin = stdin
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	err := runCommand(ctx, cmd)
+	return out.Bytes(), err
+}
+
+// ExecuteCommandStdinOutput is like ExecuteCommandStdin for commands whose output is parsed, stderr is only returned when the command fails.
+func ExecuteCommandStdinOutput(ctx context.Context, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+
+	out := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdin = stdin
+	cmd.Stdout = out
+	cmd.Stderr = stderr
+
+	if err := runCommand(ctx, cmd); err != nil {
+		return append(out.Bytes(), stderr.Bytes()...), err
+	}
+
+	return out.Bytes(), nil
+}
+
+// ExecuteShell runs a command line through the system shell, inside dir with env added to the current environment.
+func ExecuteShell(ctx context.Context, dir string, env []string, command string) ([]byte, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	cmd := exec.Command(shell, flag, command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	err := runCommand(ctx, cmd)
+	return out.Bytes(), err
+}
+
+// ExecuteCommandWriter runs a command, streaming its combined stdout and stderr to out while it runs.
+func ExecuteCommandWriter(ctx context.Context, out io.Writer, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	return runCommand(ctx, cmd)
+}
+
+// ExecuteAttached runs a command which takes over the terminal, such as an editor.
+func ExecuteAttached(ctx context.Context, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return runCommand(ctx, cmd)
+}
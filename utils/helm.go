@@ -3,16 +3,21 @@ package utils
 import (
 	"os"
 	"path"
+	"strconv"
+	"strings"
 
 	"github.com/seventv/helm-manager/v2/types"
 	"gopkg.in/yaml.v3"
 )
 
 type helmChartYaml struct {
-	Name        string `yaml:"name"`
-	Version     string `yaml:"version"`
-	AppVersion  string `yaml:"appVersion"`
-	Description string `yaml:"description"`
+	Name         string `yaml:"name"`
+	Version      string `yaml:"version"`
+	AppVersion   string `yaml:"appVersion"`
+	Description  string `yaml:"description"`
+	Dependencies []struct {
+		Name string `yaml:"name"`
+	} `yaml:"dependencies"`
 }
 
 func ParseLocalChartYaml(chart *types.HelmChart) error {
@@ -33,3 +38,105 @@ func ParseLocalChartYaml(chart *types.HelmChart) error {
 
 	return nil
 }
+
+// LocalChartHasDependencies reports whether the Chart.yaml of a local chart declares dependencies which need to be built.
+func LocalChartHasDependencies(dir string) bool {
+	data, err := os.ReadFile(path.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return false
+	}
+
+	values := helmChartYaml{}
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return false
+	}
+
+	return len(values.Dependencies) != 0
+}
+
+// CompareVersions compares two semver versions, returning -1, 0 or 1. Build metadata is ignored and prereleases sort before their release.
+func CompareVersions(a string, b string) int {
+	split := func(v string) ([]string, string) {
+		v = strings.TrimPrefix(strings.SplitN(v, "+", 2)[0], "v")
+		parts := strings.SplitN(v, "-", 2)
+		pre := ""
+		if len(parts) == 2 {
+			pre = parts[1]
+		}
+
+		return strings.Split(parts[0], "."), pre
+	}
+
+	aParts, aPre := split(a)
+	bParts, bPre := split(b)
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	default:
+		return comparePrerelease(aPre, bPre)
+	}
+}
+
+// comparePrerelease compares prerelease versions like semver does, identifier by identifier.
+// Numeric identifiers compare as numbers and sort before alphanumeric ones, so rc.9 comes before rc.10.
+func comparePrerelease(a string, b string) int {
+	aIds, bIds := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aIds) && i < len(bIds); i++ {
+		aNum, aErr := strconv.ParseUint(aIds[i], 10, 64)
+		bNum, bErr := strconv.ParseUint(bIds[i], 10, 64)
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				if aNum < bNum {
+					return -1
+				}
+
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case aIds[i] != bIds[i]:
+			if aIds[i] < bIds[i] {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	// every identifier matched, the one with more of them is newer
+	switch {
+	case len(aIds) < len(bIds):
+		return -1
+	case len(aIds) > len(bIds):
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.10", "1.0.9", 1},
+		{"1.0", "1.0.1", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0-rc.9", "1.0.0-rc.10", -1},
+		{"1.0.0-rc.1", "1.0.0-rc.1", 0},
+	}
+
+	for _, test := range tests {
+		if got := CompareVersions(test.a, test.b); got != test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+
+		if got := CompareVersions(test.b, test.a); got != -test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
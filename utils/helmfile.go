@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/seventv/helm-manager/v2/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadHelmfile decodes the first document of a helmfile, templated helmfiles cannot be read.
+// The returned int is the number of documents in the file.
+func ReadHelmfile(pth string) (types.Helmfile, int, error) {
+	helmfile := types.Helmfile{}
+
+	data, err := os.ReadFile(pth)
+	if err != nil {
+		return helmfile, 0, err
+	}
+
+	docs := 0
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := types.Helmfile{}
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if bytes.Contains(data, []byte("{{")) {
+				return helmfile, docs, fmt.Errorf("failed to parse helmfile, go templates are not supported: %v", err)
+			}
+
+			return helmfile, docs, fmt.Errorf("failed to parse helmfile, %v", err)
+		}
+
+		if docs == 0 {
+			helmfile = doc
+		}
+
+		docs++
+	}
+
+	return helmfile, docs, nil
+}
+
+// HelmfileReleaseValues merges the values files, inline values and set entries of a helmfile release in the order helmfile applies them.
+// Anything which cannot be translated is passed to report and skipped.
+func HelmfileReleaseValues(dir string, release types.HelmfileRelease, report func(format string, args ...any)) *yaml.Node {
+	merged := &yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+	}
+
+	merge := func(node *yaml.Node) {
+		if node.Kind == yaml.DocumentNode {
+			if len(node.Content) == 0 {
+				return
+			}
+
+			node = node.Content[0]
+		}
+
+		if node.Kind != yaml.MappingNode {
+			report("release %s: values must be a mapping", release.Name)
+			return
+		}
+
+		merged = MergeYaml(merged, node)
+	}
+
+	for _, value := range release.Values {
+		value := value
+		if value.Kind == yaml.MappingNode {
+			merge(&value)
+			continue
+		}
+
+		if value.Kind != yaml.ScalarNode {
+			report("release %s: values entry on line %d is not a file or a mapping", release.Name, value.Line)
+			continue
+		}
+
+		if strings.HasSuffix(value.Value, ".gotmpl") || strings.Contains(value.Value, "{{") {
+			report("release %s: templated values file %s is not supported", release.Name, value.Value)
+			continue
+		}
+
+		pth := value.Value
+		if !filepath.IsAbs(pth) {
+			pth = filepath.Join(dir, pth)
+		}
+
+		data, err := os.ReadFile(pth)
+		if err != nil {
+			report("release %s: failed to read values file %s, %v", release.Name, value.Value, err)
+			continue
+		}
+
+		if bytes.Contains(data, []byte("{{")) {
+			report("release %s: values file %s contains go templates, they are copied as is", release.Name, value.Value)
+		}
+
+		node, err := ParseYaml(data)
+		if err != nil {
+			report("release %s: failed to parse values file %s, %v", release.Name, value.Value, err)
+			continue
+		}
+
+		for _, doc := range node.Content {
+			merge(doc)
+		}
+	}
+
+	sets := func(entries []types.HelmfileSet, forceString bool) {
+		for _, set := range entries {
+			if set.File != "" {
+				report("release %s: set %s from file %s is not supported", release.Name, set.Name, set.File)
+				continue
+			}
+
+			if strings.ContainsAny(set.Name, "[]\\") {
+				report("release %s: set %s uses list indexes or escapes which are not supported", release.Name, set.Name)
+				continue
+			}
+
+			value := set.Value
+			if len(set.Values) != 0 {
+				value = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+				for i := range set.Values {
+					value.Content = append(value.Content, &set.Values[i])
+				}
+			}
+
+			if forceString && value.Kind == yaml.ScalarNode {
+				value.Tag = "!!str"
+				value.Style = yaml.DoubleQuotedStyle
+			}
+
+			keys := strings.Split(set.Name, ".")
+			node := &value
+			for i := len(keys) - 1; i >= 0; i-- {
+				parent := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				SetMappingValue(parent, keys[i], node)
+				node = parent
+			}
+
+			merge(node)
+		}
+	}
+
+	sets(release.Set, false)
+	sets(release.SetString, true)
+
+	return merged
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KubeResource is a kubernetes object as returned by `kubectl get -o json`.
+type KubeResource map[string]any
+
+func (r KubeResource) metadata() map[string]any {
+	metadata, _ := r["metadata"].(map[string]any)
+	return metadata
+}
+
+func (r KubeResource) Kind() string {
+	kind, _ := r["kind"].(string)
+	return kind
+}
+
+func (r KubeResource) Name() string {
+	name, _ := r.metadata()["name"].(string)
+	return name
+}
+
+func (r KubeResource) Namespace() string {
+	namespace, _ := r.metadata()["namespace"].(string)
+	return namespace
+}
+
+func (r KubeResource) Label(key string) string {
+	labels, _ := r.metadata()["labels"].(map[string]any)
+	value, _ := labels[key].(string)
+	return value
+}
+
+// ParseKubeResources parses the output of `kubectl get -o json`, which is either a single object or a list.
+func ParseKubeResources(data []byte) ([]KubeResource, error) {
+	obj := KubeResource{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	items, ok := obj["items"].([]any)
+	if !ok || !strings.HasSuffix(obj.Kind(), "List") {
+		return []KubeResource{obj}, nil
+	}
+
+	resources := make([]KubeResource, 0, len(items))
+	for _, item := range items {
+		if item, ok := item.(map[string]any); ok {
+			resources = append(resources, item)
+		}
+	}
+
+	return resources, nil
+}
+
+// CleanKubeResource removes the fields which are populated by the api server, leaving only what is needed to recreate the resource.
+func CleanKubeResource(r KubeResource) {
+	delete(r, "status")
+
+	metadata := r.metadata()
+	if metadata == nil {
+		return
+	}
+
+	for _, key := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink"} {
+		delete(metadata, key)
+	}
+
+	if annotations, ok := metadata["annotations"].(map[string]any); ok {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+}
+
+// MarshalKubeResources writes resources as a multi document yaml file, with apiVersion, kind and metadata first like kubectl does.
+func MarshalKubeResources(resources []KubeResource) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+
+	first := map[string]int{"apiVersion": 0, "kind": 1, "metadata": 2}
+	for _, r := range resources {
+		node := &yaml.Node{}
+		if err := node.Encode(map[string]any(r)); err != nil {
+			return nil, err
+		}
+
+		pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+		}
+
+		sort.SliceStable(pairs, func(i, j int) bool {
+			a, aOk := first[pairs[i][0].Value]
+			b, bOk := first[pairs[j][0].Value]
+			if aOk && bOk {
+				return a < b
+			}
+
+			return aOk && !bOk
+		})
+
+		node.Content = node.Content[:0]
+		for _, pair := range pairs {
+			node.Content = append(node.Content, pair[0], pair[1])
+		}
+
+		if err := enc.Encode(node); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/seventv/helm-manager/v2/types"
+)
+
+// LeasePollInterval is how often a held lease is checked again while waiting for it.
+const LeasePollInterval = 2 * time.Second
+
+// MinRenewInterval is the shortest time between two renewals of a held lease.
+const MinRenewInterval = time.Second
+
+type LeaseRequest struct {
+	Holder   string
+	Manifest string
+	TTL      time.Duration
+	Wait     time.Duration // how long to wait for a lease held by someone else, 0 fails straight away
+
+	Now   func() time.Time    // defaults to time.Now
+	Sleep func(time.Duration) // defaults to time.Sleep
+}
+
+// LeaseHeldError is returned when someone else holds the lease for longer than the request was willing to wait.
+type LeaseHeldError struct {
+	Lease types.LeaseRecord
+}
+
+func (e LeaseHeldError) Error() string {
+	return fmt.Sprintf("the cluster is locked by %s", e.Lease)
+}
+
+// HeldLease is a lease taken by this process, it has to be released when done.
+type HeldLease struct {
+	mtx     sync.Mutex
+	backend types.LeaseBackend
+	record  types.LeaseRecord
+	now     func() time.Time
+	stop    chan struct{}
+}
+
+// AcquireLease takes the lease, taking over expired leases and waiting up to req.Wait for leases held by others.
+func AcquireLease(ctx context.Context, backend types.LeaseBackend, req LeaseRequest) (*HeldLease, error) {
+	if req.Now == nil {
+		req.Now = time.Now
+	}
+	if req.Sleep == nil {
+		req.Sleep = time.Sleep
+	}
+
+	deadline := req.Now().Add(req.Wait)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		now := req.Now()
+		record := types.LeaseRecord{
+			Holder:   req.Holder,
+			Manifest: req.Manifest,
+			Acquired: now,
+			Renewed:  now,
+			TTL:      req.TTL,
+		}
+
+		current, exists, err := backend.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !exists:
+			err = backend.Create(ctx, record)
+		case current.Holder == req.Holder || current.Expired(now):
+			err = backend.Update(ctx, current, record)
+		default:
+			if !now.Before(deadline) {
+				return nil, LeaseHeldError{Lease: current}
+			}
+
+			req.Sleep(LeasePollInterval)
+			continue
+		}
+
+		if errors.Is(err, types.ErrLeaseConflict) {
+			// someone else was faster, look at what they wrote
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		// backends fill in the version, read it back so renewals do not conflict with our own write
+		if record, exists, err = backend.Get(ctx); err != nil {
+			return nil, err
+		} else if !exists || record.Holder != req.Holder {
+			continue
+		}
+
+		return &HeldLease{
+			backend: backend,
+			record:  record,
+			now:     req.Now,
+		}, nil
+	}
+}
+
+func (h *HeldLease) Record() types.LeaseRecord {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	return h.record
+}
+
+// Renew pushes the expiry of the lease forward, it fails when the lease was taken over in the meantime.
+func (h *HeldLease) Renew(ctx context.Context) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	renewed := h.record
+	renewed.Renewed = h.now()
+	if err := h.backend.Update(ctx, h.record, renewed); err != nil {
+		return err
+	}
+
+	current, exists, err := h.backend.Get(ctx)
+	if err != nil {
+		return err
+	} else if !exists || current.Holder != h.record.Holder {
+		return types.ErrLeaseConflict
+	}
+
+	h.record = current
+	return nil
+}
+
+// KeepAlive renews the lease in the background until it is released or ctx is done, onErr is called when a renewal fails.
+// Each renewal may take up to timeout.
+func (h *HeldLease) KeepAlive(ctx context.Context, timeout time.Duration, onErr func(error)) {
+	h.mtx.Lock()
+	if h.stop != nil {
+		h.mtx.Unlock()
+		return
+	}
+
+	h.stop = make(chan struct{})
+	stop := h.stop
+	interval := h.record.TTL / 3
+	h.mtx.Unlock()
+
+	// a ticker needs a positive interval and very short ttls should not hammer the backend
+	if interval < MinRenewInterval {
+		interval = MinRenewInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				renewCtx, cancel := context.WithTimeout(ctx, timeout)
+				err := h.Renew(renewCtx)
+				cancel()
+
+				if err != nil && onErr != nil {
+					onErr(err)
+				}
+			}
+		}
+	}()
+}
+
+// Release stops renewing the lease and deletes it, unless someone else took it over.
+func (h *HeldLease) Release(ctx context.Context) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if h.stop != nil {
+		close(h.stop)
+		h.stop = nil
+	}
+
+	current, exists, err := h.backend.Get(ctx)
+	if err != nil || !exists || current.Holder != h.record.Holder {
+		return err
+	}
+
+	return h.backend.Delete(ctx, current)
+}
+
+// MemoryLeaseBackend keeps the lease in memory, it behaves like the cluster backend for a single process.
+type MemoryLeaseBackend struct {
+	mtx     sync.Mutex
+	record  *types.LeaseRecord
+	version int
+}
+
+func (m *MemoryLeaseBackend) Get(_ context.Context) (types.LeaseRecord, bool, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.record == nil {
+		return types.LeaseRecord{}, false, nil
+	}
+
+	return *m.record, true, nil
+}
+
+func (m *MemoryLeaseBackend) store(lease types.LeaseRecord) {
+	m.version++
+	lease.Version = strconv.Itoa(m.version)
+	m.record = &lease
+}
+
+func (m *MemoryLeaseBackend) Create(_ context.Context, lease types.LeaseRecord) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.record != nil {
+		return types.ErrLeaseConflict
+	}
+
+	m.store(lease)
+	return nil
+}
+
+func (m *MemoryLeaseBackend) Update(_ context.Context, old types.LeaseRecord, lease types.LeaseRecord) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.record == nil || m.record.Version != old.Version {
+		return types.ErrLeaseConflict
+	}
+
+	m.store(lease)
+	return nil
+}
+
+func (m *MemoryLeaseBackend) Delete(_ context.Context, lease types.LeaseRecord) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.record == nil || m.record.Version != lease.Version {
+		return types.ErrLeaseConflict
+	}
+
+	m.record = nil
+	return nil
+}
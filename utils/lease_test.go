@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/seventv/helm-manager/v2/types"
+)
+
+// testClock is a clock which only moves when told to, sleeping moves it forward.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Sleep(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func leaseRequest(clock *testClock, holder string) LeaseRequest {
+	return LeaseRequest{
+		Holder:   holder,
+		Manifest: "test",
+		TTL:      time.Minute,
+		Now:      clock.Now,
+		Sleep:    clock.Sleep,
+	}
+}
+
+func TestAcquireLeaseFree(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	backend := &MemoryLeaseBackend{}
+
+	lease, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "a"))
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	record, exists, _ := backend.Get(context.Background())
+	if !exists || record.Holder != "a" || record.TTL != time.Minute || !record.Acquired.Equal(clock.now) {
+		t.Fatalf("unexpected stored lease %+v", record)
+	}
+
+	if lease.Record().Version != record.Version {
+		t.Fatalf("held version %q, stored version %q", lease.Record().Version, record.Version)
+	}
+}
+
+func TestAcquireLeaseHeld(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	backend := &MemoryLeaseBackend{}
+
+	if _, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "a")); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	req := leaseRequest(clock, "b")
+	req.Wait = 10 * time.Second
+
+	_, err := AcquireLease(context.Background(), backend, req)
+
+	var held LeaseHeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("expected LeaseHeldError, got %v", err)
+	} else if held.Lease.Holder != "a" {
+		t.Fatalf("expected the lease to be held by a, got %s", held.Lease.Holder)
+	}
+
+	if waited := clock.now.Sub(time.Unix(1000, 0)); waited < req.Wait {
+		t.Fatalf("gave up after %s, expected to wait %s", waited, req.Wait)
+	}
+
+	if record, _, _ := backend.Get(context.Background()); record.Holder != "a" {
+		t.Fatalf("lease was taken from a by %s", record.Holder)
+	}
+}
+
+func TestAcquireLeaseExpired(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	backend := &MemoryLeaseBackend{}
+
+	if _, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "a")); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	clock.Sleep(time.Minute)
+
+	lease, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "b"))
+	if err != nil {
+		t.Fatalf("take over: %v", err)
+	}
+
+	if record, _, _ := backend.Get(context.Background()); record.Holder != "b" || lease.Record().Holder != "b" {
+		t.Fatalf("expected b to hold the lease, got %s", record.Holder)
+	}
+}
+
+func TestAcquireLeaseReacquire(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	backend := &MemoryLeaseBackend{}
+
+	first, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "a"))
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	clock.Sleep(time.Second)
+
+	second, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "a"))
+	if err != nil {
+		t.Fatalf("reacquire: %v", err)
+	}
+
+	if second.Record().Version == first.Record().Version {
+		t.Fatalf("expected reacquiring to write a new version")
+	} else if !second.Record().Renewed.Equal(clock.now) {
+		t.Fatalf("expected the lease to be renewed at %s, got %s", clock.now, second.Record().Renewed)
+	}
+}
+
+func TestHeldLeaseRelease(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	backend := &MemoryLeaseBackend{}
+
+	lease, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "a"))
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	if err := lease.Release(context.Background()); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+
+	if _, exists, _ := backend.Get(context.Background()); exists {
+		t.Fatalf("expected the lease to be deleted")
+	}
+
+	if _, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "b")); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+}
+
+func TestHeldLeaseReleaseTakenOver(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	backend := &MemoryLeaseBackend{}
+
+	lease, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "a"))
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	clock.Sleep(time.Minute)
+	if _, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "b")); err != nil {
+		t.Fatalf("take over: %v", err)
+	}
+
+	if err := lease.Release(context.Background()); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+
+	if record, exists, _ := backend.Get(context.Background()); !exists || record.Holder != "b" {
+		t.Fatalf("releasing a taken over lease removed the lease of b")
+	}
+}
+
+func TestHeldLeaseKeepAlive(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	backend := &MemoryLeaseBackend{}
+
+	req := leaseRequest(clock, "a")
+	req.TTL = 3 * MinRenewInterval
+
+	lease, err := AcquireLease(context.Background(), backend, req)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	version := lease.Record().Version
+
+	errs := make(chan error, 1)
+	lease.KeepAlive(context.Background(), time.Second, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	defer lease.Release(context.Background())
+
+	deadline := time.Now().Add(5 * MinRenewInterval)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-errs:
+			t.Fatalf("renew: %v", err)
+		default:
+		}
+
+		if record, _, _ := backend.Get(context.Background()); record.Version != version {
+			if record.Holder != "a" || lease.Record().Version != record.Version {
+				t.Fatalf("unexpected renewed lease %+v", record)
+			}
+
+			return
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("lease was not renewed within %s", 5*MinRenewInterval)
+}
+
+func TestHeldLeaseRenewTakenOver(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	backend := &MemoryLeaseBackend{}
+
+	lease, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "a"))
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	clock.Sleep(time.Minute)
+	if _, err := AcquireLease(context.Background(), backend, leaseRequest(clock, "b")); err != nil {
+		t.Fatalf("take over: %v", err)
+	}
+
+	if err := lease.Renew(context.Background()); !errors.Is(err, types.ErrLeaseConflict) {
+		t.Fatalf("expected a conflict renewing a taken over lease, got %v", err)
+	}
+}
@@ -14,14 +14,47 @@ type LoaderOptions struct {
 	FetchingText string
 	SuccessText  string
 	FailureText  string
+	// Progress is shown after the spinner, eg. how many of a batch finished.
+	Progress func() string
+}
+
+// spinning are the loaders currently running, only the newest one draws its spinner so parallel fetches do not fight over the line.
+var spinning = struct {
+	mtx     sync.Mutex
+	loaders []*LoaderOptions
+}{}
+
+func spinnerTurn(options *LoaderOptions) bool {
+	spinning.mtx.Lock()
+	defer spinning.mtx.Unlock()
+
+	return spinning.loaders[len(spinning.loaders)-1] == options
+}
+
+func stopSpinner(options *LoaderOptions) {
+	spinning.mtx.Lock()
+	defer spinning.mtx.Unlock()
+
+	for i, l := range spinning.loaders {
+		if l == options {
+			spinning.loaders = append(spinning.loaders[:i], spinning.loaders[i+1:]...)
+			return
+		}
+	}
 }
 
 func Loader(options LoaderOptions) func(success bool) {
 	downloading := make(chan bool)
 	finished := make(chan struct{})
+
+	spinning.mtx.Lock()
+	spinning.loaders = append(spinning.loaders, &options)
+	spinning.mtx.Unlock()
+
 	go func() {
 		defer close(downloading)
 		defer close(finished)
+		defer stopSpinner(&options)
 
 		if constants.InTerm() {
 			t := time.NewTicker(200 * time.Millisecond)
@@ -31,7 +64,16 @@ func Loader(options LoaderOptions) func(success bool) {
 			for {
 				select {
 				case <-t.C:
-					zap.S().Infof("%s [%s]\r", color.YellowString(options.FetchingText), color.CyanString("%s", stages[i%len(stages)]))
+					if !spinnerTurn(&options) {
+						continue
+					}
+
+					progress := ""
+					if options.Progress != nil {
+						progress = " " + options.Progress()
+					}
+
+					zap.S().Infof("%s [%s]%s\r", color.YellowString(options.FetchingText), color.CyanString("%s", stages[i%len(stages)]), progress)
 					i++
 				case success := <-downloading:
 					if success {
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path"
 
@@ -10,33 +11,87 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+func ManifestPath(cwd string) string {
+	return path.Join(cwd, "manifest.yaml")
+}
+
 func ReadManifest(cwd string) error {
-	data, err := os.ReadFile(path.Join(cwd, "manifest.yaml"))
+	data, err := os.ReadFile(ManifestPath(cwd))
 	if err != nil {
 		return nil
 	}
 
-	if err := yaml.Unmarshal(data, types.GlobalManifest); err != nil {
-		return err
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(data, node); err != nil {
+		return fmt.Errorf("failed to parse manifest, %v", err)
+	}
+
+	from := types.ManifestAPIVersion
+	if !NodeIsZero(node) {
+		from, err = MigrateManifestNode(node)
+		if err != nil {
+			return err
+		}
+
+		if from != types.ManifestAPIVersion {
+			// line numbers in errors will refer to the migrated manifest, but that is still close enough to be useful
+			if data, err = yaml.Marshal(node); err != nil {
+				return fmt.Errorf("failed to marshal migrated manifest, %v", err)
+			}
+		}
+	}
+
+	if err := DecodeStrict(data, types.GlobalManifest); err != nil {
+		return fmt.Errorf("invalid manifest %s\n  %v", ManifestPath(cwd), err)
 	}
 
+	types.GlobalManifest.APIVersion = types.ManifestAPIVersion
+	types.GlobalManifest.SourceAPIVersion = from
 	types.GlobalManifest.Exists = true
 
 	return nil
 }
 
+// WriteManifest writes the manifest at the current apiVersion, an older manifest read before is migrated by writing it.
 func WriteManifest(cwd string) {
 	buf := &bytes.Buffer{}
 	enc := yaml.NewEncoder(buf)
 	enc.SetIndent(2)
 
+	types.GlobalManifest.APIVersion = types.ManifestAPIVersion
+
 	err := enc.Encode(types.GlobalManifest)
 	if err != nil {
 		logger.Fatal("failed to marshal manifest")
 	}
 
-	err = os.WriteFile(path.Join(cwd, "manifest.yaml"), buf.Bytes(), 0644)
+	err = os.WriteFile(ManifestPath(cwd), buf.Bytes(), 0644)
 	if err != nil {
 		logger.Fatal("failed to write manifest")
 	}
 }
+
+// ParseManifest decodes a manifest from data, migrating it to the current apiVersion.
+// Unlike ReadManifest unknown keys are ignored, it is meant for older copies of the manifest such as from git history.
+func ParseManifest(data []byte) (types.Manifest, error) {
+	manifest := types.Manifest{}
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(data, node); err != nil {
+		return manifest, fmt.Errorf("failed to parse manifest, %v", err)
+	}
+
+	if NodeIsZero(node) {
+		return manifest, nil
+	}
+
+	if _, err := MigrateManifestNode(node); err != nil {
+		return manifest, err
+	}
+
+	if err := node.Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("invalid manifest, %v", err)
+	}
+
+	return manifest, nil
+}
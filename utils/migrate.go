@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/seventv/helm-manager/v2/types"
+	"gopkg.in/yaml.v3"
+)
+
+type Migration struct {
+	From string
+	To   string
+
+	// Manifest upgrades the manifest document in place.
+	Manifest func(node *yaml.Node) error
+}
+
+var migrations = map[string]Migration{}
+
+func RegisterMigration(migration Migration) {
+	if _, ok := migrations[migration.From]; ok {
+		panic(fmt.Sprintf("migration from %s already registered", migration.From))
+	}
+
+	migrations[migration.From] = migration
+}
+
+func init() {
+	RegisterMigration(Migration{
+		From: types.ManifestAPIVersionV1,
+		To:   types.ManifestAPIVersionV2,
+		Manifest: func(node *yaml.Node) error {
+			SetMappingValue(node, "apiVersion", &yaml.Node{
+				Kind:  yaml.ScalarNode,
+				Tag:   "!!str",
+				Value: types.ManifestAPIVersionV2,
+			})
+
+			return nil
+		},
+	})
+}
+
+// MigrationsFrom returns the ordered list of migrations needed to bring a manifest of the given version up to date.
+func MigrationsFrom(version string) ([]Migration, error) {
+	if version == "" {
+		version = types.ManifestAPIVersionV1
+	}
+
+	result := []Migration{}
+	seen := map[string]bool{}
+	for version != types.ManifestAPIVersion {
+		migration, ok := migrations[version]
+		if !ok || seen[version] {
+			return nil, fmt.Errorf("unsupported manifest apiVersion \"%s\", this build supports up to \"%s\"", version, types.ManifestAPIVersion)
+		}
+
+		seen[version] = true
+		result = append(result, migration)
+		version = migration.To
+	}
+
+	return result, nil
+}
+
+// ManifestVersion returns the apiVersion of a parsed manifest document.
+func ManifestVersion(node *yaml.Node) string {
+	if value := MappingValue(node, "apiVersion"); value != nil && value.Value != "" {
+		return value.Value
+	}
+
+	return types.ManifestAPIVersionV1
+}
+
+// MigrateManifestNode applies all migrations needed to the manifest document, and returns the version it was at before.
+func MigrateManifestNode(node *yaml.Node) (string, error) {
+	from := ManifestVersion(node)
+
+	chain, err := MigrationsFrom(from)
+	if err != nil {
+		return from, err
+	}
+
+	for _, migration := range chain {
+		if migration.Manifest == nil {
+			continue
+		}
+
+		if err := migration.Manifest(node); err != nil {
+			return from, fmt.Errorf("failed to migrate manifest from %s to %s, %v", migration.From, migration.To, err)
+		}
+	}
+
+	return from, nil
+}
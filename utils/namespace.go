@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/seventv/helm-manager/v2/types"
+)
+
+// NamespaceObjectName is the name of the ResourceQuota and LimitRange created for managed namespaces.
+const NamespaceObjectName = "helm-manager"
+
+func stringMap(m map[string]string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+
+	return out
+}
+
+// NamespaceResources builds the objects applied for a namespace of the manifest.
+func NamespaceResources(ns types.ManifestNamespace) []KubeResource {
+	metadata := map[string]any{"name": ns.Name}
+	if len(ns.Labels) != 0 {
+		metadata["labels"] = stringMap(ns.Labels)
+	}
+	if len(ns.Annotations) != 0 {
+		metadata["annotations"] = stringMap(ns.Annotations)
+	}
+
+	resources := []KubeResource{{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   metadata,
+	}}
+
+	if len(ns.Quota) != 0 {
+		resources = append(resources, KubeResource{
+			"apiVersion": "v1",
+			"kind":       "ResourceQuota",
+			"metadata":   map[string]any{"name": NamespaceObjectName, "namespace": ns.Name},
+			"spec":       map[string]any{"hard": stringMap(ns.Quota)},
+		})
+	}
+
+	if ns.LimitRange != nil {
+		limit := map[string]any{"type": "Container"}
+		for key, values := range map[string]map[string]string{
+			"default":        ns.LimitRange.Default,
+			"defaultRequest": ns.LimitRange.DefaultRequest,
+			"max":            ns.LimitRange.Max,
+			"min":            ns.LimitRange.Min,
+		} {
+			if len(values) != 0 {
+				limit[key] = stringMap(values)
+			}
+		}
+
+		resources = append(resources, KubeResource{
+			"apiVersion": "v1",
+			"kind":       "LimitRange",
+			"metadata":   map[string]any{"name": NamespaceObjectName, "namespace": ns.Name},
+			"spec":       map[string]any{"limits": []any{limit}},
+		})
+	}
+
+	return resources
+}
+
+// diffStringMap reports keys of want which are missing or different in have, keys only in have are ignored.
+func diffStringMap(what string, want map[string]string, have map[string]any) []string {
+	keys := make([]string, 0, len(want))
+	for key := range want {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	drift := []string{}
+	for _, key := range keys {
+		value, ok := have[key]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s %s is missing, expected %s", what, key, want[key]))
+		} else if fmt.Sprint(value) != want[key] {
+			drift = append(drift, fmt.Sprintf("%s %s is %v, expected %s", what, key, value, want[key]))
+		}
+	}
+
+	return drift
+}
+
+// NamespaceDrift compares a namespace of the manifest to the objects found in the cluster.
+// Quantities are compared as written, so the manifest should use the form the api server returns (eg. 1Gi over 1024Mi).
+func NamespaceDrift(ns types.ManifestNamespace, live []KubeResource) []string {
+	byKind := map[string]KubeResource{}
+	for _, r := range live {
+		byKind[r.Kind()] = r
+	}
+
+	namespace, ok := byKind["Namespace"]
+	if !ok {
+		return []string{"namespace does not exist"}
+	}
+
+	labels, _ := namespace.metadata()["labels"].(map[string]any)
+	annotations, _ := namespace.metadata()["annotations"].(map[string]any)
+
+	drift := diffStringMap("label", ns.Labels, labels)
+	drift = append(drift, diffStringMap("annotation", ns.Annotations, annotations)...)
+
+	if len(ns.Quota) != 0 {
+		if quota, ok := byKind["ResourceQuota"]; !ok {
+			drift = append(drift, fmt.Sprintf("resource quota %s does not exist", NamespaceObjectName))
+		} else {
+			spec, _ := quota["spec"].(map[string]any)
+			hard, _ := spec["hard"].(map[string]any)
+			drift = append(drift, diffStringMap("quota", ns.Quota, hard)...)
+		}
+	}
+
+	if ns.LimitRange != nil {
+		if limitRange, ok := byKind["LimitRange"]; !ok {
+			drift = append(drift, fmt.Sprintf("limit range %s does not exist", NamespaceObjectName))
+		} else {
+			limit := map[string]any{}
+			spec, _ := limitRange["spec"].(map[string]any)
+			limits, _ := spec["limits"].([]any)
+			for _, l := range limits {
+				if l, ok := l.(map[string]any); ok && l["type"] == "Container" {
+					limit = l
+				}
+			}
+
+			for _, field := range []struct {
+				key  string
+				want map[string]string
+			}{
+				{"default", ns.LimitRange.Default},
+				{"defaultRequest", ns.LimitRange.DefaultRequest},
+				{"max", ns.LimitRange.Max},
+				{"min", ns.LimitRange.Min},
+			} {
+				have, _ := limit[field.key].(map[string]any)
+				drift = append(drift, diffStringMap("limit range "+field.key, field.want, have)...)
+			}
+		}
+	}
+
+	return drift
+}
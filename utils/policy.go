@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/seventv/helm-manager/v2/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadPolicy reads the policy file, the second value is false when there is no policy file.
+func ReadPolicy(pth string) (types.Policy, bool, error) {
+	policy := types.Policy{}
+
+	data, err := os.ReadFile(pth)
+	if errors.Is(err, os.ErrNotExist) {
+		return policy, false, nil
+	} else if err != nil {
+		return policy, false, err
+	}
+
+	if err := DecodeStrict(data, &policy); err != nil {
+		return policy, true, fmt.Errorf("invalid policy %s\n  %v", pth, err)
+	}
+
+	return policy, true, nil
+}
+
+// ParseRenderedResources parses multi document yaml as produced by `helm template` or written in a single.
+func ParseRenderedResources(data []byte) ([]KubeResource, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	resources := []KubeResource{}
+	for {
+		// decoding into KubeResource would make nested mappings KubeResource too, the checks expect plain maps
+		obj := map[string]any{}
+		if err := dec.Decode(&obj); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		r := KubeResource(obj)
+		if len(r) == 0 {
+			continue
+		}
+
+		if items, ok := r["items"].([]any); ok && strings.HasSuffix(r.Kind(), "List") {
+			for _, item := range items {
+				if item, ok := item.(map[string]any); ok {
+					resources = append(resources, item)
+				}
+			}
+		} else {
+			resources = append(resources, r)
+		}
+	}
+
+	return resources, nil
+}
+
+type kubeContainer struct {
+	path string
+	spec map[string]any
+}
+
+// podSpec finds the pod template of workload resources.
+func (r KubeResource) podSpec() map[string]any {
+	spec, _ := r["spec"].(map[string]any)
+
+	switch r.Kind() {
+	case "Pod":
+		return spec
+	case "CronJob":
+		jobTemplate, _ := spec["jobTemplate"].(map[string]any)
+		spec, _ = jobTemplate["spec"].(map[string]any)
+	}
+
+	template, _ := spec["template"].(map[string]any)
+	podSpec, _ := template["spec"].(map[string]any)
+
+	return podSpec
+}
+
+func (r KubeResource) containers() []kubeContainer {
+	podSpec := r.podSpec()
+
+	containers := []kubeContainer{}
+	for _, key := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		list, _ := podSpec[key].([]any)
+		for i, c := range list {
+			if c, ok := c.(map[string]any); ok {
+				name, _ := c["name"].(string)
+				if name == "" {
+					name = fmt.Sprintf("%s[%d]", key, i)
+				}
+
+				containers = append(containers, kubeContainer{path: name, spec: c})
+			}
+		}
+	}
+
+	return containers
+}
+
+// ImageTag returns the tag of an image reference, empty when it has none. The second value is true when the image is pinned by digest.
+func ImageTag(image string) (string, bool) {
+	if strings.Contains(image, "@") {
+		return "", true
+	}
+
+	// a colon before the last slash belongs to the registry port
+	idx := strings.LastIndex(image, ":")
+	if idx == -1 || idx < strings.LastIndex(image, "/") {
+		return "", false
+	}
+
+	return image[idx+1:], false
+}
+
+func checkNoLatestTag(r KubeResource) []string {
+	msgs := []string{}
+	for _, c := range r.containers() {
+		image, _ := c.spec["image"].(string)
+		tag, digest := ImageTag(image)
+		if digest {
+			continue
+		}
+
+		if tag == "" {
+			msgs = append(msgs, fmt.Sprintf("container %s image %s has no tag, which means latest", c.path, image))
+		} else if tag == "latest" {
+			msgs = append(msgs, fmt.Sprintf("container %s image %s uses the latest tag", c.path, image))
+		}
+	}
+
+	return msgs
+}
+
+func checkRequireLimits(r KubeResource, rule types.PolicyRule) []string {
+	required := rule.Resources
+	if len(required) == 0 {
+		required = types.DefaultLimitResources
+	}
+
+	msgs := []string{}
+	for _, c := range r.containers() {
+		resources, _ := c.spec["resources"].(map[string]any)
+		limits, _ := resources["limits"].(map[string]any)
+
+		missing := []string{}
+		for _, name := range required {
+			if value, ok := limits[name]; !ok || value == nil || fmt.Sprint(value) == "" {
+				missing = append(missing, name)
+			}
+		}
+
+		if len(missing) != 0 {
+			msgs = append(msgs, fmt.Sprintf("container %s has no limit for %s", c.path, strings.Join(missing, ", ")))
+		}
+	}
+
+	return msgs
+}
+
+func checkNoPrivileged(r KubeResource) []string {
+	msgs := []string{}
+	for _, c := range r.containers() {
+		securityContext, _ := c.spec["securityContext"].(map[string]any)
+		if privileged, _ := securityContext["privileged"].(bool); privileged {
+			msgs = append(msgs, fmt.Sprintf("container %s is privileged", c.path))
+		}
+	}
+
+	return msgs
+}
+
+func checkRequireLabels(r KubeResource, rule types.PolicyRule) []string {
+	labels, _ := r.metadata()["labels"].(map[string]any)
+
+	missing := []string{}
+	for _, label := range rule.Labels {
+		if _, ok := labels[label]; !ok {
+			missing = append(missing, label)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("missing labels %s", strings.Join(missing, ", "))}
+}
+
+// EvaluatePolicy runs every rule of the policy against the resources.
+func EvaluatePolicy(policy types.Policy, resources []KubeResource) []types.PolicyViolation {
+	violations := []types.PolicyViolation{}
+	for _, rule := range policy.Rules {
+		for _, r := range resources {
+			if !rule.AppliesTo(r.Kind()) {
+				continue
+			}
+
+			var msgs []string
+			switch rule.Check {
+			case types.PolicyCheckNoLatestTag:
+				msgs = checkNoLatestTag(r)
+			case types.PolicyCheckRequireLimits:
+				msgs = checkRequireLimits(r, rule)
+			case types.PolicyCheckNoPrivileged:
+				msgs = checkNoPrivileged(r)
+			case types.PolicyCheckRequireLabels:
+				msgs = checkRequireLabels(r, rule)
+			}
+
+			for _, msg := range msgs {
+				violations = append(violations, types.PolicyViolation{
+					Rule:     rule.Name,
+					Severity: rule.EffectiveSeverity(),
+					Resource: fmt.Sprintf("%s/%s", r.Kind(), r.Name()),
+					Message:  msg,
+				})
+			}
+		}
+	}
+
+	return violations
+}
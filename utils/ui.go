@@ -3,10 +3,13 @@ package utils
 import (
 	"fmt"
 	"sort"
+	"strings"
 
+	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
 	"github.com/seventv/helm-manager/v2/logger"
 	"github.com/seventv/helm-manager/v2/types"
+	"go.uber.org/zap"
 )
 
 func Selector(short string, labelLong string, help bool, search bool, options []types.Selectable) int {
@@ -68,6 +71,115 @@ func Selector(short string, labelLong string, help bool, search bool, options []
 	return items[i].idx
 }
 
+// MultiSelector lets the user pick any number of options, returning the indices of the picked options in the order they are listed in options.
+// Picking an option toggles it, the first two entries finish the selection and toggle all options.
+func MultiSelector(short string, labelLong string, search bool, options []types.Selectable) []int {
+	type selection struct {
+		Label   string
+		Details string
+		idx     int
+
+		Match func(input string) bool
+	}
+
+	order := make([]int, len(options))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return options[order[i]].Label() < options[order[j]].Label()
+	})
+
+	picked := make([]bool, len(options))
+	matchAll := func(string) bool { return true }
+
+	cursor, scroll := 0, 0
+	for {
+		count := 0
+		for _, p := range picked {
+			if p {
+				count++
+			}
+		}
+
+		items := []selection{
+			{Label: color.GreenString("✔ Done (%d selected)", count), idx: -1, Match: matchAll},
+			{Label: color.YellowString("* Toggle all (ignores search)"), idx: -2, Match: matchAll},
+		}
+
+		for _, idx := range order {
+			box := "[ ]"
+			if picked[idx] {
+				box = color.GreenString("[x]")
+			}
+
+			items = append(items, selection{
+				Label:   fmt.Sprintf("%s %s", box, options[idx].Label()),
+				Details: options[idx].Details(),
+				Match:   options[idx].Match,
+				idx:     idx,
+			})
+		}
+
+		prompt := promptui.Select{
+			Label:        labelLong,
+			Items:        items,
+			HideSelected: true,
+			Size:         10,
+			Templates: &promptui.SelectTemplates{
+				Label:    "{{ .Label }}",
+				Active:   "➔ {{ .Label }}",
+				Inactive: "  {{ .Label }}",
+				Details:  "{{ .Details }}",
+			},
+		}
+
+		if search {
+			prompt.Searcher = func(input string, index int) bool {
+				return items[index].Match(input)
+			}
+		}
+
+		i, _, err := prompt.RunCursorAt(cursor, scroll)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		cursor = i
+		if cursor >= prompt.Size {
+			scroll = cursor - prompt.Size + 1
+		} else {
+			scroll = 0
+		}
+
+		switch items[i].idx {
+		case -1:
+			result := []int{}
+			labels := []string{}
+			for idx, p := range picked {
+				if p {
+					result = append(result, idx)
+					labels = append(labels, options[idx].Selected())
+				}
+			}
+
+			if short != "" {
+				zap.S().Infof("%s %s", color.New(color.Faint).Sprintf("%s:", short), strings.Join(labels, ", "))
+			}
+
+			return result
+		case -2:
+			all := count != len(picked)
+			for idx := range picked {
+				picked[idx] = all
+			}
+		default:
+			picked[items[i].idx] = !picked[items[i].idx]
+		}
+	}
+}
+
 type PromptMessage[T comparable] struct {
 	Label       string
 	IsConfirm   bool
@@ -4,27 +4,10 @@ import (
 	"crypto/sha256"
 	"io"
 	"os"
-	"os/exec"
 	"path"
 	"strings"
-
-	"go.uber.org/zap"
 )
 
-func ExecuteCommand(name string, args ...string) ([]byte, error) {
-	zap.S().Debugf("%s %s", name, strings.Join(args, " "))
-	return exec.Command(name, args...).CombinedOutput()
-}
-
-func ExecuteCommandStdin(stdin io.Reader, name string, args ...string) ([]byte, error) {
-	zap.S().Debugf("%s %s", name, strings.Join(args, " "))
-	cmd := exec.Command(name, args...)
-
-	cmd.Stdin = stdin
-
-	return cmd.CombinedOutput()
-}
-
 func MergeStrings(lines ...string) string {
 	prunedLines := []string{}
 	for _, line := range lines {
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/jinzhu/copier"
 	"github.com/seventv/helm-manager/v2/logger"
@@ -361,3 +363,113 @@ func ToDocument(node *yaml.Node) *yaml.Node {
 
 	return newDefault
 }
+
+func MappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+
+		node = node.Content[0]
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func SetMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			node.Content = append(node.Content, &yaml.Node{
+				Kind: yaml.MappingNode,
+				Tag:  "!!map",
+			})
+		}
+
+		node = node.Content[0]
+	}
+
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+
+	// new keys are prepended so that identifying fields such as apiVersion stay at the top of the file
+	node.Content = append([]*yaml.Node{{
+		Kind:  yaml.ScalarNode,
+		Tag:   "!!str",
+		Value: key,
+	}, value}, node.Content...)
+}
+
+func DecodeStrict(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NodeAtPath resolves a path such as releases[2].chart.repo inside a node, returning the deepest node that could be found.
+func NodeAtPath(node *yaml.Node, pth string) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) != 0 {
+		node = node.Content[0]
+	}
+
+	for _, part := range strings.Split(pth, ".") {
+		if part == "" || node == nil {
+			continue
+		}
+
+		key := part
+		indexes := []int{}
+		if idx := strings.Index(part, "["); idx != -1 {
+			key = part[:idx]
+			for _, index := range strings.Split(strings.TrimSuffix(part[idx+1:], "]"), "][") {
+				i, err := strconv.Atoi(index)
+				if err != nil {
+					return node
+				}
+
+				indexes = append(indexes, i)
+			}
+		}
+
+		if key != "" {
+			value := MappingValue(node, key)
+			if value == nil {
+				return node
+			}
+
+			node = value
+		}
+
+		for _, i := range indexes {
+			if node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+				return node
+			}
+
+			node = node.Content[i]
+		}
+	}
+
+	return node
+}